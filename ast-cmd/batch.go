@@ -0,0 +1,121 @@
+package main
+
+import (
+	"HTTP-boilerplate/ast"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type batchResult struct {
+	Row        int    `json:"row"`
+	Team       string `json:"team"`
+	Submission string `json:"submission"`
+	gradeResult
+}
+
+// batchCommand grades a CSV of team answers. Columns are team, submission and optionally submitted_after (minutes).
+// Earlier rows of the same team count as previous submissions. A header row starting with "team" is skipped.
+func batchCommand(args []string) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	problemPath := flags.String("problem", "", "problem file (JSON)")
+	answers := flags.String("answers", "", "CSV file with team answers, - for standard input")
+	seed := flags.Int64("seed", 0, "seed for sampled testing (random if 0)")
+	format := flags.String("format", "csv", "output format: csv, json or text")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *problemPath == "" || *answers == "" {
+		fmt.Fprintln(os.Stderr, "-problem and -answers are required")
+		return exitUsage
+	}
+
+	problem, err := readProblemFile(*problemPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read the problem file:", err.Error())
+		return exitError
+	}
+	task, err := problem.task(*seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid problem:", err.Error())
+		return exitSolutionError
+	}
+
+	file, err := readInput(*answers)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to read the answers:", err.Error())
+		return exitError
+	}
+	reader := csv.NewReader(strings.NewReader(file))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to parse the answers:", err.Error())
+		return exitError
+	}
+
+	results := make([]batchResult, 0)
+	attempts := make(map[string]int)
+	code := exitAccepted
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "team") {
+			continue
+		}
+		if len(record) < 2 {
+			fmt.Fprintf(os.Stderr, "Row %d: expected at least 2 columns (team, submission)\n", i+1)
+			return exitUsage
+		}
+		team := strings.TrimSpace(record[0])
+		submittedAfter := 0
+		if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+			submittedAfter, err = strconv.Atoi(strings.TrimSpace(record[2]))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Row %d: invalid submitted_after %s\n", i+1, record[2])
+				return exitUsage
+			}
+		}
+
+		submission := ast.MinifyString(strings.TrimSpace(record[1]))
+		result, err := grade(problem, task, submission, attempts[team], submittedAfter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Row %d: judging failed: %s\n", i+1, err.Error())
+			return exitError
+		}
+		attempts[team]++
+		code = max(code, verdictExitCode(result.Verdict))
+		results = append(results, batchResult{Row: i + 1, Team: team, Submission: submission, gradeResult: result})
+	}
+
+	switch *format {
+	case "json":
+		if printJSON(results) != exitAccepted {
+			return exitError
+		}
+	case "text":
+		for _, v := range results {
+			fmt.Printf("%s: %s, %d/%d points\n", v.Team, v.Verdict, v.Score, v.MaxScore)
+		}
+	default:
+		writer := csv.NewWriter(os.Stdout)
+		writer.Write([]string{"row", "team", "submission", "verdict", "score", "max_score", "penalty_time", "correct_test_cases", "wrong_test_cases"})
+		for _, v := range results {
+			writer.Write([]string{
+				strconv.Itoa(v.Row),
+				v.Team,
+				v.Submission,
+				v.Verdict,
+				strconv.Itoa(v.Score),
+				strconv.Itoa(v.MaxScore),
+				strconv.Itoa(v.PenaltyTime),
+				strconv.Itoa(v.CorrectTestCases),
+				strconv.Itoa(v.WrongTestCases),
+			})
+		}
+		writer.Flush()
+	}
+
+	return code
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"HTTP-boilerplate/ast"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// maxTruthTableInputs limits the truth table to about a million rows.
+const maxTruthTableInputs = 20
+
+func buildExpression(value string, file string, name string) (*ast.AST, string, int) {
+	s, err := expression(value, file, name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return nil, "", exitUsage
+	}
+	a, err := ast.BuildAST(s)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "AST build failed", err.Error())
+		return nil, "", exitCompilationFailure
+	}
+	return a, s, exitAccepted
+}
+
+func printJSON(v any) int {
+	j, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "JSON marshal failed", err.Error())
+		return exitError
+	}
+	fmt.Println(string(j))
+	return exitAccepted
+}
+
+func truthTableCommand(args []string) int {
+	flags := flag.NewFlagSet("truthtable", flag.ContinueOnError)
+	expr := flags.String("expr", "", "expression")
+	exprFile := flags.String("expr-file", "", "file with the expression, - for standard input")
+	format := flags.String("format", "text", "output format: text, csv or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	a, _, code := buildExpression(*expr, *exprFile, "expr")
+	if a == nil {
+		return code
+	}
+	inputs := ast.MergeInputs(a)
+	if len(inputs) > maxTruthTableInputs {
+		fmt.Fprintf(os.Stderr, "Too many inputs (%d), the truth table is limited to %d\n", len(inputs), maxTruthTableInputs)
+		return exitUsage
+	}
+	rows, err := ast.TruthTable(a, inputs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Evaluation failed", err.Error())
+		return exitRuntimeError
+	}
+
+	switch *format {
+	case "json":
+		return printJSON(map[string]any{"inputs": string(inputs), "rows": rows})
+	case "csv":
+		for _, v := range inputs {
+			fmt.Printf("%s,", string(v))
+		}
+		fmt.Println("OUT")
+		for _, row := range rows {
+			for _, v := range row.Vector {
+				fmt.Printf("%s,", string(v))
+			}
+			fmt.Println(boolToBit(row.Output))
+		}
+	default:
+		fmt.Printf("%s | OUT\n", string(inputs))
+		for _, row := range rows {
+			fmt.Printf("%s | %d\n", row.Vector, boolToBit(row.Output))
+		}
+	}
+	return exitAccepted
+}
+
+func boolToBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func equivCommand(args []string) int {
+	flags := flag.NewFlagSet("equiv", flag.ContinueOnError)
+	expr1 := flags.String("a", "", "first expression")
+	expr1File := flags.String("a-file", "", "file with the first expression")
+	expr2 := flags.String("b", "", "second expression")
+	expr2File := flags.String("b-file", "", "file with the second expression")
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	a, _, code := buildExpression(*expr1, *expr1File, "a")
+	if a == nil {
+		return code
+	}
+	b, _, code := buildExpression(*expr2, *expr2File, "b")
+	if b == nil {
+		return code
+	}
+
+	// izraza primerjamo nad unijo vhodov, saj lahko ekvivalenten izraz kakšnega vhoda sploh ne uporablja
+	inputs := ast.MergeInputs(a, b)
+	rowsA, err := ast.TruthTable(a, inputs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Evaluation failed", err.Error())
+		return exitRuntimeError
+	}
+	rowsB, err := ast.TruthTable(b, inputs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Evaluation failed", err.Error())
+		return exitRuntimeError
+	}
+
+	differences := make([]string, 0)
+	for i := range rowsA {
+		if rowsA[i].Output != rowsB[i].Output {
+			differences = append(differences, rowsA[i].Vector)
+		}
+	}
+
+	if *format == "json" {
+		printJSON(map[string]any{"inputs": string(inputs), "equivalent": len(differences) == 0, "differences": differences})
+	} else if len(differences) == 0 {
+		fmt.Println("Expressions are equivalent.")
+	} else {
+		fmt.Printf("Expressions differ on %d/%d rows (inputs: %s):\n", len(differences), len(rowsA), string(inputs))
+		for _, v := range differences {
+			fmt.Println(v)
+		}
+	}
+
+	if len(differences) != 0 {
+		return exitWrongAnswer
+	}
+	return exitAccepted
+}
+
+func minimizeCommand(args []string) int {
+	flags := flag.NewFlagSet("minimize", flag.ContinueOnError)
+	expr := flags.String("expr", "", "expression")
+	exprFile := flags.String("expr-file", "", "file with the expression, - for standard input")
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	a, s, code := buildExpression(*expr, *exprFile, "expr")
+	if a == nil {
+		return code
+	}
+	minimized, err := ast.Minimize(a)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Minimization failed", err.Error())
+		return exitError
+	}
+
+	if *format == "json" {
+		return printJSON(map[string]any{
+			"original":         s,
+			"original_length":  ast.ASTLength(a, 0),
+			"minimized":        ast.String(minimized),
+			"minimized_length": ast.ASTLength(minimized, 0),
+		})
+	}
+	fmt.Println(ast.String(minimized))
+	return exitAccepted
+}
+
+func renderCommand(args []string) int {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	expr := flags.String("expr", "", "expression")
+	exprFile := flags.String("expr-file", "", "file with the expression, - for standard input")
+	format := flags.String("format", "tree", "output format: tree, dot, json or text")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	a, _, code := buildExpression(*expr, *exprFile, "expr")
+	if a == nil {
+		return code
+	}
+
+	switch *format {
+	case "dot":
+		fmt.Print(ast.RenderDOT(a))
+	case "json":
+		return printJSON(a)
+	case "text":
+		fmt.Println(ast.String(a))
+	case "tree":
+		fmt.Print(ast.RenderTree(a))
+	default:
+		fmt.Fprintln(os.Stderr, "Unknown format", *format)
+		return exitUsage
+	}
+	return exitAccepted
+}
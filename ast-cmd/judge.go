@@ -0,0 +1,94 @@
+package main
+
+import (
+	"HTTP-boilerplate/ast"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func judgeCommand(args []string) int {
+	flags := flag.NewFlagSet("judge", flag.ContinueOnError)
+	problemPath := flags.String("problem", "", "problem file (JSON)")
+	solution := flags.String("solution", "", "reference solution(s), semicolon separated (overrides the problem file)")
+	solutionFile := flags.String("solution-file", "", "file with the reference solution(s)")
+	submission := flags.String("submission", "", "submitted solution")
+	submissionFile := flags.String("submission-file", "", "file with the submitted solution, - for standard input")
+	points := flags.Int("points", 0, "maximum points (overrides the problem file, defaults to 100)")
+	policy := flags.String("policy", "", "scoring policy (overrides the problem file)")
+	previous := flags.Int("previous", 0, "number of previous submissions")
+	submittedAfter := flags.Int("submitted-after", 0, "minutes after the start of the competition")
+	seed := flags.Int64("seed", 0, "seed for sampled testing (random if 0)")
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	problem := problemFile{Points: 100}
+	if *problemPath != "" {
+		p, err := readProblemFile(*problemPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to read the problem file:", err.Error())
+			return exitError
+		}
+		problem = p
+	}
+	if *solution != "" || *solutionFile != "" {
+		s, err := expression(*solution, *solutionFile, "solution")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return exitUsage
+		}
+		problem.Solutions = ast.ParseSolutions(s)
+	}
+	if len(problem.Solutions) == 0 {
+		fmt.Fprintln(os.Stderr, "-problem or -solution is required")
+		return exitUsage
+	}
+	if *points != 0 {
+		problem.Points = *points
+	}
+	if *policy != "" {
+		problem.ScoringPolicy = *policy
+	}
+
+	sub, err := expression(*submission, *submissionFile, "submission")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitUsage
+	}
+
+	task, err := problem.task(*seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid problem:", err.Error())
+		return exitSolutionError
+	}
+
+	result, err := grade(problem, task, sub, *previous, *submittedAfter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Judging failed:", err.Error())
+		return exitError
+	}
+
+	if *format == "json" {
+		j, err := json.MarshalIndent(result, "", "\t")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "JSON marshal failed", err.Error())
+			return exitError
+		}
+		fmt.Println(string(j))
+	} else {
+		fmt.Println("Judging complete!")
+		fmt.Println("Verdict:", result.Verdict)
+		fmt.Printf("Score: %d/%d\n", result.Score, result.MaxScore)
+		fmt.Printf("Correct test cases/wrong test cases: %d/%d\n", result.CorrectTestCases, result.WrongTestCases)
+		if result.SampleSize != 0 {
+			fmt.Printf("Sample size: %d, seed: %d, confidence: %.4f\n", result.SampleSize, result.Seed, result.Confidence)
+		}
+		fmt.Println("Evaluation log:")
+		fmt.Print(result.Log)
+	}
+
+	return verdictExitCode(result.Verdict)
+}
@@ -1,49 +1,75 @@
 package main
 
 import (
-	"HTTP-boilerplate/ast"
-	"encoding/json"
 	"fmt"
+	"os"
 )
 
-func main() {
-	solutionText := "AND(NOT(A), B)"
-	submissionText := "AND(a, b)"
+// Exit codes reflect the verdict, so that problem setters can script checks.
+const (
+	exitAccepted           = 0
+	exitError              = 1
+	exitUsage              = 2
+	exitPartial            = 3
+	exitWrongAnswer        = 4
+	exitCompilationFailure = 5
+	exitRuntimeError       = 6
+	exitSolutionError      = 7
+)
 
-	subS := ast.MinifyString(submissionText)
-	sub, err := ast.BuildAST(subS)
-	if err != nil {
-		fmt.Println("AST build failed", err.Error())
-		return
-	}
-	j, err := json.MarshalIndent(sub, "", "\t")
-	if err != nil {
-		fmt.Println("JSON marshal failed", err.Error())
-		return
+func verdictExitCode(verdict string) int {
+	switch verdict {
+	case "AC":
+		return exitAccepted
+	case "PART":
+		return exitPartial
+	case "WA":
+		return exitWrongAnswer
+	case "CF":
+		return exitCompilationFailure
+	case "RTE":
+		return exitRuntimeError
+	case "SOL_CF", "SOL_RTE":
+		return exitSolutionError
 	}
-	fmt.Println("Contestant solution AST", string(j))
+	return exitError
+}
 
-	solS := ast.MinifyString(solutionText)
-	sol, err := ast.BuildAST(solS)
-	if err != nil {
-		fmt.Println("AST solution build failed", err.Error())
-		return
-	}
+type command struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
 
-	solution, err := ast.TestDigitalSolution(sub, sol)
-	if err != nil {
-		fmt.Println("Digital solution testing failed", err.Error())
-		return
-	}
+var commands = []command{
+	{"judge", "judge a submission against a solution or a problem file", judgeCommand},
+	{"truthtable", "print the truth table of an expression", truthTableCommand},
+	{"equiv", "check whether two expressions are equivalent", equivCommand},
+	{"minimize", "minimize an expression into a sum of products", minimizeCommand},
+	{"render", "render an expression as a tree, DOT graph, JSON or text", renderCommand},
+	{"batch", "grade a CSV of team answers against a problem file", batchCommand},
+}
 
-	if solution.Verdict == "AC" && solS != subS {
-		fmt.Printf("Applying PARTIAL verdict! Contestant: %s, Judge: %s.\n", subS, solS)
-		solution.Verdict = "PARTIAL"
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: ast-cmd <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, v := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", v.name, v.description)
 	}
+	fmt.Fprintln(os.Stderr, "\nRun ast-cmd <command> -h for the flags of a command.")
+	fmt.Fprintln(os.Stderr, "\nExit codes: 0 AC, 1 error, 2 usage, 3 PART, 4 WA (or not equivalent), 5 CF, 6 RTE, 7 solution error.")
+}
 
-	fmt.Println("Judging complete!")
-	fmt.Println("Verdict:", solution.Verdict)
-	fmt.Printf("Correct test cases/wrong test cases: %d/%d\n", solution.CorrectTestCases, solution.WrongTestCases)
-	fmt.Println("Evaluation log:")
-	fmt.Printf(solution.EvaluationLog)
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
+	for _, v := range commands {
+		if v.name == os.Args[1] {
+			os.Exit(v.run(os.Args[2:]))
+		}
+	}
+	usage()
+	os.Exit(exitUsage)
 }
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"HTTP-boilerplate/ast"
+	"HTTP-boilerplate/judge"
+	"HTTP-boilerplate/scoring"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// problemFile is the JSON problem description used by the judge and batch commands, e.g.
+//
+//	{"name": "Adder", "solutions": ["XOR(A,B)"], "points": 100, "row_weights": "11=5!"}
+type problemFile struct {
+	Name              string          `json:"name"`
+	Solutions         []string        `json:"solutions"`
+	Points            int             `json:"points"`
+	IsBaseLogicOnly   bool            `json:"is_base_logic_only"`
+	SampleSize        int             `json:"sample_size"`
+	MandatoryVectors  string          `json:"mandatory_vectors"`
+	RowWeights        string          `json:"row_weights"`
+	ScoringPolicy     string          `json:"scoring_policy"`
+	ScoringParameters json.RawMessage `json:"scoring_parameters"`
+}
+
+func readProblemFile(path string) (problemFile, error) {
+	problem := problemFile{Points: 100}
+	file, err := readInput(path)
+	if err != nil {
+		return problem, err
+	}
+	err = json.Unmarshal([]byte(file), &problem)
+	return problem, err
+}
+
+// readInput reads the whole file, "-" being the standard input.
+func readInput(path string) (string, error) {
+	if path == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		return string(b), err
+	}
+	b, err := os.ReadFile(path)
+	return string(b), err
+}
+
+// expression returns the expression given either directly or through a file.
+func expression(value string, file string, name string) (string, error) {
+	if value != "" && file != "" {
+		return "", errors.New(fmt.Sprintf("only one of -%s and -%s-file can be given", name, name))
+	}
+	if file != "" {
+		s, err := readInput(file)
+		if err != nil {
+			return "", err
+		}
+		value = s
+	}
+	value = ast.MinifyString(strings.TrimSpace(value))
+	if value == "" {
+		return "", errors.New(fmt.Sprintf("-%s or -%s-file is required", name, name))
+	}
+	return value, nil
+}
+
+func (problem problemFile) task(seed int64) (judge.Task, error) {
+	solutions := make([]string, 0)
+	for _, v := range problem.Solutions {
+		solutions = append(solutions, ast.MinifyString(strings.TrimSpace(v)))
+	}
+	weights, err := ast.ParseRowWeights(problem.RowWeights)
+	if err != nil {
+		return judge.Task{}, err
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	task := judge.Task{
+		Solutions:        solutions,
+		IsBaseLogicOnly:  problem.IsBaseLogicOnly,
+		SampleSize:       problem.SampleSize,
+		MandatoryVectors: ast.ParseVectors(problem.MandatoryVectors),
+		Weights:          weights,
+		Seed:             seed,
+	}
+	return task, judge.VerifyReferences(task)
+}
+
+type gradeResult struct {
+	Verdict          string  `json:"verdict"`
+	Score            int     `json:"score"`
+	MaxScore         int     `json:"max_score"`
+	PenaltyTime      int     `json:"penalty_time"`
+	CorrectTestCases int     `json:"correct_test_cases"`
+	WrongTestCases   int     `json:"wrong_test_cases"`
+	Reference        int     `json:"reference"`
+	SampleSize       int     `json:"sample_size"`
+	Seed             int64   `json:"seed"`
+	Confidence       float64 `json:"confidence"`
+	ScoringPolicy    string  `json:"scoring_policy"`
+	Log              string  `json:"log"`
+}
+
+// grade judges and scores the submission exactly as the server does.
+func grade(problem problemFile, task judge.Task, submission string, previous int, submittedAfter int) (gradeResult, error) {
+	scorer, err := scoring.Get(problem.ScoringPolicy)
+	if err != nil {
+		return gradeResult{}, err
+	}
+	parameters, err := scoring.ParseParameters(string(problem.ScoringParameters))
+	if err != nil {
+		return gradeResult{}, err
+	}
+
+	evaluation, err := judge.Evaluate(task, submission)
+	if err != nil {
+		return gradeResult{}, err
+	}
+
+	result := gradeResult{
+		Verdict:          evaluation.Verdict,
+		MaxScore:         problem.Points,
+		CorrectTestCases: evaluation.CorrectTestCases,
+		WrongTestCases:   evaluation.WrongTestCases,
+		Reference:        evaluation.Reference + 1,
+		Confidence:       evaluation.Confidence,
+		Log:              evaluation.EvaluationLog,
+	}
+	if evaluation.Sampled {
+		result.SampleSize = evaluation.SampleSize
+		result.Seed = evaluation.Seed
+	}
+	if evaluation.Verdict == "CF" || evaluation.Verdict == "SOL_CF" {
+		return result, nil
+	}
+
+	score := scorer.Score(evaluation.ScoringInput(problem.Points, previous, submittedAfter), parameters)
+	result.Score = score.Score
+	result.PenaltyTime = score.PenaltyTime
+	result.ScoringPolicy = scorer.Name()
+	result.Log += fmt.Sprintf("Scoring policy: %s, parameters: %s.\n", scorer.Name(), parameters.String())
+	result.Log += score.Log
+	return result, nil
+}
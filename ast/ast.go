@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"hash/fnv"
 	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -205,6 +209,71 @@ type DigitalSolutionEvaluation struct {
 	WrongTestCases   int
 	EvaluationLog    string
 	Verdict          string
+
+	Sampled    bool
+	SampleSize int
+	Seed       int64
+	Confidence float64 // confidence, that less than SampledErrorRate of the input space is wrong
+
+	CorrectWeight   float64  // sum of weights of correctly answered rows
+	TotalWeight     float64  // sum of weights of all answered rows
+	FailedMandatory []string // mandatory rows, that were answered wrongly
+
+	UniqueVectors int // number of distinct input vectors, that were tested
+
+	log strings.Builder
+}
+
+func (dse *DigitalSolutionEvaluation) logf(format string, a ...any) {
+	fmt.Fprintf(&dse.log, format, a...)
+}
+
+// SampledErrorRate is the fraction of the input space, for which the confidence of a sampled AC verdict is reported.
+const SampledErrorRate = 0.01
+
+type TestOptions struct {
+	SampleSize       int      // number of random input vectors to test, 0 means exhaustive testing
+	Seed             int64    // seed for the random input vectors
+	MandatoryVectors []string // input vectors (e.g. "0110", inputs are sorted alphabetically), that are always tested when sampling
+	Weights          []RowWeight
+}
+
+// ValidateVector checks whether the vector is a binary string for the given number of inputs.
+func ValidateVector(vector string, inputs int) error {
+	if len(vector) != inputs {
+		return errors.New(fmt.Sprintf("Vector %s is invalid. Expected %d inputs, got %d", vector, inputs, len(vector)))
+	}
+	for _, v := range vector {
+		if v != '0' && v != '1' {
+			return errors.New(fmt.Sprintf("Vector %s is invalid. Invalid character %s", vector, string(v)))
+		}
+	}
+	return nil
+}
+
+// ParseSolutions parses a semicolon (or newline) separated list of solutions.
+func ParseSolutions(s string) []string {
+	solutions := make([]string, 0)
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		v = MinifyString(strings.TrimSpace(v))
+		if v == "" {
+			continue
+		}
+		solutions = append(solutions, v)
+	}
+	return solutions
+}
+
+// ParseVectors parses a comma separated list of input vectors.
+func ParseVectors(s string) []string {
+	vectors := make([]string, 0)
+	for _, v := range strings.Split(MinifyString(s), ",") {
+		if v == "" {
+			continue
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors
 }
 
 func recursiveBuildInputs(a *AST, m *map[rune]bool) *map[rune]bool {
@@ -228,6 +297,10 @@ func BuildInputs(a *AST) *[]rune {
 	for i := range m {
 		r = append(r, i)
 	}
+	// inputs are sorted, so that test case numbers (and sampled vectors) are reproducible
+	sort.Slice(r, func(i, j int) bool {
+		return r[i] < r[j]
+	})
 	return &r
 }
 
@@ -316,47 +389,254 @@ func boolToInt(a bool) int {
 }
 
 func TestDigitalSolution(aSubmission *AST, aSolution *AST) (*DigitalSolutionEvaluation, error) {
-	inputs := *BuildInputs(aSolution)
+	return TestDigitalSolutionWithOptions(aSubmission, aSolution, TestOptions{})
+}
+
+// testCase evaluates both the solution and the submission on a single input vector and logs the result.
+// It returns false, if the testing must be aborted (solution failed).
+func testCase(aSubmission *AST, aSolution *AST, dse *DigitalSolutionEvaluation, inputs []rune, weights []RowWeight, n int, vector string) bool {
 	m := make(map[rune]bool)
-	dse := DigitalSolutionEvaluation{
-		CorrectTestCases: 0,
-		WrongTestCases:   0,
-		EvaluationLog:    "",
-		Verdict:          "",
-	}
-	for n := 0; n < int(math.Pow(2, float64(len(inputs)))); n++ {
-		f := fmt.Sprint(len(inputs))
-		if len(inputs) < 10 {
-			f = "0" + f
+	for i, v := range []rune(vector) {
+		m[inputs[i]] = v != '0'
+	}
+	sol, err := evaluate(aSolution, &m)
+	if err != nil {
+		dse.logf("Solution evaluation failed on test case %d. Error: %s.\n", n+1, err.Error())
+		dse.Verdict = "SOL_RTE" // Solution Runtime error
+		return false
+	}
+	sub, err := evaluate(aSubmission, &m)
+	if err != nil {
+		dse.logf("Submission evaluation failed on test case %d. Error: %s.\n", n+1, err.Error())
+		dse.Verdict = "RTE"
+		return true
+	}
+	// teže se seštevajo le, če so nastavljene, sicer se uporabi navadno razmerje testnih primerov
+	weight, mandatory := rowWeight(weights, vector)
+	info := ""
+	if len(weights) != 0 {
+		info = fmt.Sprintf(" Weight: %g.", weight)
+		if mandatory {
+			info += " Mandatory."
 		}
-		format := fmt.Sprintf("%%%sb", f)
-		ns := fmt.Sprintf(format, int64(n))
-		for i, v := range []rune(ns) {
-			m[inputs[i]] = v != '0'
+	} else {
+		weight = 0
+	}
+	dse.TotalWeight += weight
+	dse.UniqueVectors++
+	if sub != sol {
+		dse.WrongTestCases++
+		dse.logf("Wrong answer on test case %d (%s)! Contestant: %d, Judge: %d.%s\n", n+1, vector, boolToInt(sub), boolToInt(sol), info)
+		dse.Verdict = "WA"
+		if mandatory {
+			dse.FailedMandatory = append(dse.FailedMandatory, vector)
 		}
-		sol, err := evaluate(aSolution, &m)
-		if err != nil {
-			dse.EvaluationLog += fmt.Sprintf("Solution evaluation failed on test case %d. Error: %s.\n", n+1, err.Error())
-			dse.Verdict = "SOL_RTE" // Solution Runtime error
-			return &dse, nil
+		return true
+	}
+	dse.logf("Correct answer on test case %d (%s)! Contestant: %d, Judge: %d.%s\n", n+1, vector, boolToInt(sub), boolToInt(sol), info)
+	dse.CorrectTestCases++
+	dse.CorrectWeight += weight
+	return true
+}
+
+func formatVector(n int64, length int) string {
+	if length == 0 {
+		return ""
+	}
+	return fmt.Sprintf(fmt.Sprintf("%%0%db", length), n)
+}
+
+// TestDigitalSolutionWithOptions tests the submission against the solution. If options.SampleSize is set
+// and is smaller than the number of all possible input vectors, only a seeded random sample of distinct
+// vectors (along with all options.MandatoryVectors) is tested, otherwise the whole truth table is enumerated.
+func TestDigitalSolutionWithOptions(aSubmission *AST, aSolution *AST, options TestOptions) (*DigitalSolutionEvaluation, error) {
+	dse := &DigitalSolutionEvaluation{
+		Confidence: 1,
+	}
+	if err := testDigitalSolution(aSubmission, aSolution, options, dse); err != nil {
+		return nil, err
+	}
+	dse.EvaluationLog = dse.log.String()
+	return dse, nil
+}
+
+func testDigitalSolution(aSubmission *AST, aSolution *AST, options TestOptions, dse *DigitalSolutionEvaluation) error {
+	inputs := *BuildInputs(aSolution)
+
+	for _, v := range options.MandatoryVectors {
+		if err := ValidateVector(v, len(inputs)); err != nil {
+			return err
+		}
+	}
+	if err := ValidateRowWeights(options.Weights, len(inputs)); err != nil {
+		return err
+	}
+	if len(options.Weights) != 0 {
+		patterns := make([]string, 0)
+		for _, v := range options.Weights {
+			patterns = append(patterns, v.String())
+		}
+		dse.logf("Row weights (first matching pattern applies, other rows have a weight of 1): %s.\n", strings.Join(patterns, ", "))
+	}
+
+	total := int64(1) << len(inputs)
+	if options.SampleSize <= 0 || int64(options.SampleSize) >= total {
+		for n := int64(0); n < total; n++ {
+			if !testCase(aSubmission, aSolution, dse, inputs, options.Weights, int(n), formatVector(n, len(inputs))) {
+				return nil
+			}
+		}
+		if dse.Verdict == "" {
+			dse.Verdict = "AC"
 		}
-		sub, err := evaluate(aSubmission, &m)
+		logWeights(dse, options.Weights)
+		return nil
+	}
+
+	dse.Sampled = true
+	dse.SampleSize = options.SampleSize
+	dse.Seed = options.Seed
+
+	// mandatory rows without wildcards are always tested too
+	vectors := slices.Clone(options.MandatoryVectors)
+	for _, v := range options.Weights {
+		if v.Mandatory && !strings.Contains(v.Pattern, "X") {
+			vectors = append(vectors, v.Pattern)
+		}
+	}
+
+	// vsak vektor se testira največ enkrat, da ponovitve ne napihnejo pokritosti
+	seen := make(map[int64]bool)
+	mandatory := make([]int64, 0, len(vectors))
+	for _, v := range vectors {
+		n, err := strconv.ParseInt(v, 2, 64)
 		if err != nil {
-			dse.EvaluationLog += fmt.Sprintf("Submission evaluation failed on test case %d. Error: %s.\n", n+1, err.Error())
-			dse.Verdict = "RTE"
-			continue
+			return err
+		}
+		if !seen[n] {
+			seen[n] = true
+			mandatory = append(mandatory, n)
+		}
+	}
+	sample := sampleVectors(total, options.SampleSize, options.Seed)
+
+	dse.logf("Input space has %d vectors (inputs: %s). Testing %d random vectors with seed %d and %d mandatory vectors.\n", total, string(inputs), options.SampleSize, options.Seed, len(mandatory))
+
+	n := 0
+	for _, v := range mandatory {
+		if !testCase(aSubmission, aSolution, dse, inputs, options.Weights, n, formatVector(v, len(inputs))) {
+			return nil
 		}
-		if sub != sol {
-			dse.WrongTestCases++
-			dse.EvaluationLog += fmt.Sprintf("Wrong answer on test case %d (%s)! Contestant: %d, Judge: %d.\n", n+1, ns, boolToInt(sub), boolToInt(sol))
-			dse.Verdict = "WA"
+		n++
+	}
+
+	random := 0
+	for _, v := range sample {
+		if seen[v] {
 			continue
 		}
-		dse.EvaluationLog += fmt.Sprintf("Correct answer on test case %d (%s)! Contestant: %d, Judge: %d.\n", n+1, ns, boolToInt(sub), boolToInt(sol))
-		dse.CorrectTestCases++
+		if !testCase(aSubmission, aSolution, dse, inputs, options.Weights, n, formatVector(v, len(inputs))) {
+			return nil
+		}
+		random++
+		n++
 	}
+	dse.logf("Tested %d distinct vectors (%d random, %d mandatory).\n", dse.UniqueVectors, random, len(mandatory))
+
 	if dse.Verdict == "" {
 		dse.Verdict = "AC"
+		// vzorčenje brez ponavljanja je vsaj tako zanesljivo kot s ponavljanjem, zato je ocena konzervativna
+		dse.Confidence = 1 - math.Pow(1-SampledErrorRate, float64(random))
+		dse.logf(
+			"All sampled vectors passed. With %.1f %% confidence, less than %.2f %% of the input space disagrees with the judge (95 %% upper bound: %.4f %%).\n",
+			dse.Confidence*100, SampledErrorRate*100, (1-math.Pow(0.05, 1/float64(max(random, 1))))*100)
+	}
+	logWeights(dse, options.Weights)
+	return nil
+}
+
+// sampleVectors returns count distinct input vectors from [0, total), drawn with a partial Fisher-Yates
+// shuffle, that only remembers the swapped positions. The order depends only on the seed.
+func sampleVectors(total int64, count int, seed int64) []int64 {
+	random := rand.New(rand.NewSource(seed))
+	swapped := make(map[int64]int64)
+	at := func(i int64) int64 {
+		if v, ok := swapped[i]; ok {
+			return v
+		}
+		return i
+	}
+	sample := make([]int64, 0, count)
+	for i := int64(0); i < int64(count) && i < total; i++ {
+		j := i + random.Int63n(total-i)
+		v := at(j)
+		swapped[j] = at(i)
+		sample = append(sample, v)
+	}
+	return sample
+}
+
+// MaxEquivalenceInputs limits the number of inputs, for which Equivalent compares the whole truth table.
+const MaxEquivalenceInputs = 16
+
+// Equivalent reports whether a agrees with b on the input vectors of b. Unlike TestDigitalSolutionWithOptions it
+// doesn't log anything and stops at the first disagreement. If b has more than MaxEquivalenceInputs inputs, only
+// options.MandatoryVectors and a seeded sample of options.SampleSize distinct vectors (or 2^MaxEquivalenceInputs
+// of them, if the sample size isn't set) are compared.
+func Equivalent(a *AST, b *AST, options TestOptions) (bool, error) {
+	inputs := *BuildInputs(b)
+	total := int64(1) << len(inputs)
+	m := make(map[rune]bool)
+	agrees := func(n int64) (bool, error) {
+		for i, v := range inputs {
+			m[v] = n&(1<<(len(inputs)-1-i)) != 0
+		}
+		want, err := evaluate(b, &m)
+		if err != nil {
+			return false, err
+		}
+		got, err := evaluate(a, &m)
+		return err == nil && got == want, nil
+	}
+
+	vectors := make([]int64, 0)
+	if len(inputs) <= MaxEquivalenceInputs {
+		for n := int64(0); n < total; n++ {
+			vectors = append(vectors, n)
+		}
+	} else {
+		for _, v := range options.MandatoryVectors {
+			if err := ValidateVector(v, len(inputs)); err != nil {
+				return false, err
+			}
+			n, err := strconv.ParseInt(v, 2, 64)
+			if err != nil {
+				return false, err
+			}
+			vectors = append(vectors, n)
+		}
+		size := options.SampleSize
+		if size <= 0 {
+			size = 1 << MaxEquivalenceInputs
+		}
+		vectors = append(vectors, sampleVectors(total, size, options.Seed)...)
+	}
+
+	for _, n := range vectors {
+		ok, err := agrees(n)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func logWeights(dse *DigitalSolutionEvaluation, weights []RowWeight) {
+	if len(weights) == 0 {
+		return
+	}
+	dse.logf("Weighted correctness: %g/%g.\n", dse.CorrectWeight, dse.TotalWeight)
+	if len(dse.FailedMandatory) != 0 {
+		dse.logf("Mandatory rows answered wrongly: %s.\n", strings.Join(dse.FailedMandatory, ", "))
 	}
-	return &dse, nil
 }
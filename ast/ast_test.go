@@ -0,0 +1,120 @@
+package ast
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+var testedVector = regexp.MustCompile(`test case \d+ \(([01]+)\)`)
+
+func testedVectors(log string) []string {
+	vectors := make([]string, 0)
+	for _, match := range testedVector.FindAllStringSubmatch(log, -1) {
+		vectors = append(vectors, match[1])
+	}
+	return vectors
+}
+
+func mustBuildAST(t *testing.T, s string) *AST {
+	t.Helper()
+	a, err := BuildAST(s)
+	if err != nil {
+		t.Fatalf("BuildAST(%q): %v", s, err)
+	}
+	return a
+}
+
+func TestSamplingIsDeterministicPerSeed(t *testing.T) {
+	solution := mustBuildAST(t, "XOR(XOR(XOR(A,B),XOR(C,D)),XOR(XOR(E,F),XOR(G,H)))")
+	submission := mustBuildAST(t, "XOR(XOR(XOR(A,B),XOR(C,D)),XOR(XOR(E,F),AND(G,H)))")
+	options := TestOptions{SampleSize: 100, Seed: 42, MandatoryVectors: []string{"00000000", "11111111"}}
+
+	first, err := TestDigitalSolutionWithOptions(submission, solution, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := TestDigitalSolutionWithOptions(submission, solution, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.EvaluationLog != second.EvaluationLog || first.Verdict != second.Verdict {
+		t.Fatal("the same seed produced different evaluations")
+	}
+
+	options.Seed = 43
+	other, err := TestDigitalSolutionWithOptions(submission, solution, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Equal(testedVectors(first.EvaluationLog), testedVectors(other.EvaluationLog)) {
+		t.Fatal("different seeds sampled the same vectors")
+	}
+}
+
+func TestSamplingTestsDistinctVectors(t *testing.T) {
+	solution := mustBuildAST(t, "OR(AND(A,B),AND(C,D))")
+	// 16 vrstic, vzorec 15 bi ob vzorčenju s ponavljanjem skoraj gotovo vseboval ponovitve
+	options := TestOptions{SampleSize: 15, Seed: 7, MandatoryVectors: []string{"0000", "0000", "1111"}}
+
+	evaluation, err := TestDigitalSolutionWithOptions(solution, solution, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors := testedVectors(evaluation.EvaluationLog)
+	unique := slices.Clone(vectors)
+	slices.Sort(unique)
+	unique = slices.Compact(unique)
+	if len(unique) != len(vectors) {
+		t.Fatalf("tested %d vectors, only %d distinct", len(vectors), len(unique))
+	}
+	if evaluation.UniqueVectors != len(vectors) {
+		t.Fatalf("UniqueVectors = %d, tested %d", evaluation.UniqueVectors, len(vectors))
+	}
+	if vectors[0] != "0000" || vectors[1] != "1111" {
+		t.Fatalf("mandatory vectors not tested first: %v", vectors[:2])
+	}
+	if !evaluation.Sampled || evaluation.Verdict != "AC" {
+		t.Fatalf("got sampled=%v verdict=%s", evaluation.Sampled, evaluation.Verdict)
+	}
+}
+
+func TestSampleVectors(t *testing.T) {
+	sample := sampleVectors(1000, 1000, 1)
+	seen := make(map[int64]bool)
+	for _, v := range sample {
+		if v < 0 || v >= 1000 || seen[v] {
+			t.Fatalf("invalid or repeated vector %d", v)
+		}
+		seen[v] = true
+	}
+	if len(seen) != 1000 {
+		t.Fatalf("got %d vectors, want 1000", len(seen))
+	}
+	if !slices.Equal(sampleVectors(1<<40, 20, 5), sampleVectors(1<<40, 20, 5)) {
+		t.Fatal("sample is not deterministic")
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	solution := mustBuildAST(t, "NOT(AND(A,B))")
+	equivalent, err := Equivalent(mustBuildAST(t, "OR(NOT(A),NOT(B))"), solution, TestOptions{})
+	if err != nil || !equivalent {
+		t.Fatalf("De Morgan: equivalent=%v err=%v", equivalent, err)
+	}
+	equivalent, err = Equivalent(mustBuildAST(t, "NOT(OR(A,B))"), solution, TestOptions{})
+	if err != nil || equivalent {
+		t.Fatalf("NOR: equivalent=%v err=%v", equivalent, err)
+	}
+	equivalent, err = Equivalent(mustBuildAST(t, "NOT(AND(A,C))"), solution, TestOptions{})
+	if err != nil || equivalent {
+		t.Fatalf("foreign input: equivalent=%v err=%v", equivalent, err)
+	}
+
+	// 24 vhodov se primerja le na vzorcu
+	wide := "XOR(XOR(XOR(AND(A,B),AND(C,D)),XOR(AND(E,F),AND(G,H))),XOR(XOR(AND(I,J),AND(K,L)),XOR(AND(M,N),XOR(XOR(AND(O,P),AND(Q,R)),XOR(AND(S,T),XOR(AND(U,V),AND(W,X)))))))"
+	equivalent, err = Equivalent(mustBuildAST(t, wide), mustBuildAST(t, wide), TestOptions{SampleSize: 1000, Seed: 3})
+	if err != nil || !equivalent {
+		t.Fatalf("wide: equivalent=%v err=%v", equivalent, err)
+	}
+}
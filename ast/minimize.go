@@ -0,0 +1,180 @@
+package ast
+
+import (
+	"errors"
+	"math/bits"
+	"sort"
+)
+
+// MaxMinimizeInputs limits the number of inputs for minimization, as Quine-McCluskey grows exponentially.
+const MaxMinimizeInputs = 16
+
+// implicant is a product term. Bits set in mask are "don't care", the remaining bits must equal value.
+type implicant struct {
+	value uint32
+	mask  uint32
+}
+
+func (i implicant) covers(minterm uint32) bool {
+	return minterm&^i.mask == i.value&^i.mask
+}
+
+// primeImplicants finds all prime implicants of the minterms (Quine-McCluskey).
+func primeImplicants(minterms []uint32) []implicant {
+	current := make([]implicant, 0)
+	for _, v := range minterms {
+		current = append(current, implicant{value: v})
+	}
+	primes := make([]implicant, 0)
+	for len(current) != 0 {
+		combined := make(map[implicant]bool)
+		used := make([]bool, len(current))
+		for i := 0; i < len(current); i++ {
+			for j := i + 1; j < len(current); j++ {
+				if current[i].mask != current[j].mask {
+					continue
+				}
+				diff := current[i].value ^ current[j].value
+				if bits.OnesCount32(diff) != 1 {
+					continue
+				}
+				used[i] = true
+				used[j] = true
+				combined[implicant{value: current[i].value &^ diff, mask: current[i].mask | diff}] = true
+			}
+		}
+		seen := make(map[implicant]bool)
+		for i, v := range current {
+			if !used[i] && !seen[v] {
+				primes = append(primes, v)
+				seen[v] = true
+			}
+		}
+		current = make([]implicant, 0)
+		for v := range combined {
+			current = append(current, v)
+		}
+		sort.Slice(current, func(i, j int) bool {
+			if current[i].mask != current[j].mask {
+				return current[i].mask < current[j].mask
+			}
+			return current[i].value < current[j].value
+		})
+	}
+	return primes
+}
+
+// coverMinterms chooses the essential prime implicants first and then greedily the ones covering most of the
+// remaining minterms.
+func coverMinterms(primes []implicant, minterms []uint32) []implicant {
+	uncovered := make(map[uint32]bool)
+	for _, v := range minterms {
+		uncovered[v] = true
+	}
+	chosen := make([]implicant, 0)
+	taken := make([]bool, len(primes))
+
+	for _, m := range minterms {
+		covering := -1
+		count := 0
+		for i, p := range primes {
+			if p.covers(m) {
+				covering = i
+				count++
+			}
+		}
+		if count == 1 && !taken[covering] {
+			taken[covering] = true
+			chosen = append(chosen, primes[covering])
+			for v := range uncovered {
+				if primes[covering].covers(v) {
+					delete(uncovered, v)
+				}
+			}
+		}
+	}
+
+	for len(uncovered) != 0 {
+		best := -1
+		bestCount := 0
+		for i, p := range primes {
+			if taken[i] {
+				continue
+			}
+			count := 0
+			for v := range uncovered {
+				if p.covers(v) {
+					count++
+				}
+			}
+			// ob enakem pokritju raje izberemo implikant z manj literali
+			if count > bestCount || (count == bestCount && count != 0 && bits.OnesCount32(p.mask) > bits.OnesCount32(primes[best].mask)) {
+				best = i
+				bestCount = count
+			}
+		}
+		taken[best] = true
+		chosen = append(chosen, primes[best])
+		for v := range uncovered {
+			if primes[best].covers(v) {
+				delete(uncovered, v)
+			}
+		}
+	}
+	return chosen
+}
+
+func chain(t int, asts []*AST) *AST {
+	a := asts[0]
+	for _, v := range asts[1:] {
+		a = &AST{Type: t, SubEntity1: a, SubEntity2: v}
+	}
+	return a
+}
+
+// Minimize returns a small (not necessarily minimal) sum-of-products form of the AST, built only from AND, OR and NOT gates.
+// Constant functions are expressed as AND(X,NOT(X)) or OR(X,NOT(X)), X being the first input.
+func Minimize(a *AST) (*AST, error) {
+	inputs := MergeInputs(a)
+	if len(inputs) > MaxMinimizeInputs {
+		return nil, errors.New("too many inputs for minimization")
+	}
+	rows, err := TruthTable(a, inputs)
+	if err != nil {
+		return nil, err
+	}
+	minterms := make([]uint32, 0)
+	for i, v := range rows {
+		if v.Output {
+			minterms = append(minterms, uint32(i))
+		}
+	}
+
+	first := &AST{Type: INPUT, Input: inputs[0]}
+	notFirst := &AST{Type: NOT, SubEntity1: first}
+	if len(minterms) == 0 {
+		return &AST{Type: AND, SubEntity1: first, SubEntity2: notFirst}, nil
+	}
+	if len(minterms) == len(rows) {
+		return &AST{Type: OR, SubEntity1: first, SubEntity2: notFirst}, nil
+	}
+
+	products := make([]*AST, 0)
+	for _, v := range coverMinterms(primeImplicants(minterms), minterms) {
+		literals := make([]*AST, 0)
+		for i, input := range inputs {
+			// prvi vhod je najpomembnejši bit vektorja
+			bit := uint32(1) << (len(inputs) - 1 - i)
+			if v.mask&bit != 0 {
+				continue
+			}
+			literal := &AST{Type: INPUT, Input: input}
+			if v.value&bit == 0 {
+				literal = &AST{Type: NOT, SubEntity1: literal}
+			}
+			literals = append(literals, literal)
+		}
+		products = append(products, chain(AND, literals))
+	}
+	return chain(OR, products), nil
+}
@@ -0,0 +1,58 @@
+package ast
+
+import "testing"
+
+func TestMinimize(t *testing.T) {
+	for _, s := range []string{
+		"AND(A,B)",
+		"XOR(A,B)",
+		"NAND(NOR(A,B),XNOR(C,D))",
+		"OR(AND(A,NOT(A)),B)",
+		"AND(A,NOT(A))",
+		"OR(A,NOT(A))",
+	} {
+		a := mustBuildAST(t, s)
+		minimized, err := Minimize(a)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if !VerifyAgainstBase(minimized) {
+			t.Errorf("%s: minimized form %s isn't built from base gates", s, String(minimized))
+		}
+		inputs := MergeInputs(a)
+		want, err := TruthTable(a, inputs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := TruthTable(minimized, inputs)
+		if err != nil {
+			t.Fatalf("%s: minimized form %s: %v", s, String(minimized), err)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: minimized form %s differs on %s", s, String(minimized), want[i].Vector)
+			}
+		}
+	}
+}
+
+func TestMinimizeSimplifies(t *testing.T) {
+	// A·B + A·¬B = A
+	minimized, err := Minimize(mustBuildAST(t, "OR(AND(A,B),AND(A,NOT(B)))"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if minimized.Type != INPUT || minimized.Input != 'A' {
+		t.Errorf("got %s, want A", String(minimized))
+	}
+}
+
+func TestMinimizeInputLimit(t *testing.T) {
+	s := "A"
+	for r := 'B'; r <= 'A'+MaxMinimizeInputs; r++ {
+		s = "AND(" + s + "," + string(r) + ")"
+	}
+	if _, err := Minimize(mustBuildAST(t, s)); err == nil {
+		t.Errorf("minimization of %d inputs was accepted", MaxMinimizeInputs+1)
+	}
+}
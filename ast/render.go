@@ -0,0 +1,107 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+func typeName(t int) string {
+	switch t {
+	case NOT:
+		return "NOT"
+	case AND:
+		return "AND"
+	case NAND:
+		return "NAND"
+	case OR:
+		return "OR"
+	case NOR:
+		return "NOR"
+	case XOR:
+		return "XOR"
+	case XNOR:
+		return "XNOR"
+	}
+	return "INPUT"
+}
+
+// String converts the AST back into its (minified) textual representation, e.g. AND(A,NOT(B)).
+func String(a *AST) string {
+	if a.Type == INPUT {
+		return string(a.Input)
+	}
+	if a.Type == NOT {
+		return fmt.Sprintf("NOT(%s)", String(a.SubEntity1))
+	}
+	return fmt.Sprintf("%s(%s,%s)", typeName(a.Type), String(a.SubEntity1), String(a.SubEntity2))
+}
+
+func recursivelyRenderTree(a *AST, prefix string, last bool, sb *strings.Builder) {
+	branch := "├── "
+	next := prefix + "│   "
+	if last {
+		branch = "└── "
+		next = prefix + "    "
+	}
+	label := typeName(a.Type)
+	if a.Type == INPUT {
+		label = string(a.Input)
+	}
+	sb.WriteString(prefix + branch + label + "\n")
+	if a.Type == INPUT {
+		return
+	}
+	if a.Type == NOT {
+		recursivelyRenderTree(a.SubEntity1, next, true, sb)
+		return
+	}
+	recursivelyRenderTree(a.SubEntity1, next, false, sb)
+	recursivelyRenderTree(a.SubEntity2, next, true, sb)
+}
+
+// RenderTree renders the AST as an ASCII tree.
+func RenderTree(a *AST) string {
+	sb := strings.Builder{}
+	label := typeName(a.Type)
+	if a.Type == INPUT {
+		label = string(a.Input)
+	}
+	sb.WriteString(label + "\n")
+	if a.Type == INPUT {
+		return sb.String()
+	}
+	if a.Type == NOT {
+		recursivelyRenderTree(a.SubEntity1, "", true, &sb)
+		return sb.String()
+	}
+	recursivelyRenderTree(a.SubEntity1, "", false, &sb)
+	recursivelyRenderTree(a.SubEntity2, "", true, &sb)
+	return sb.String()
+}
+
+func recursivelyRenderDOT(a *AST, id *int, sb *strings.Builder) int {
+	current := *id
+	*id++
+	if a.Type == INPUT {
+		sb.WriteString(fmt.Sprintf("\tn%d [label=\"%s\", shape=circle];\n", current, string(a.Input)))
+		return current
+	}
+	sb.WriteString(fmt.Sprintf("\tn%d [label=\"%s\", shape=box];\n", current, typeName(a.Type)))
+	sub1 := recursivelyRenderDOT(a.SubEntity1, id, sb)
+	sb.WriteString(fmt.Sprintf("\tn%d -> n%d;\n", sub1, current))
+	if a.Type != NOT {
+		sub2 := recursivelyRenderDOT(a.SubEntity2, id, sb)
+		sb.WriteString(fmt.Sprintf("\tn%d -> n%d;\n", sub2, current))
+	}
+	return current
+}
+
+// RenderDOT renders the AST as a Graphviz DOT circuit, with inputs flowing towards the output gate.
+func RenderDOT(a *AST) string {
+	sb := strings.Builder{}
+	sb.WriteString("digraph circuit {\n\trankdir=LR;\n")
+	id := 0
+	recursivelyRenderDOT(a, &id, &sb)
+	sb.WriteString("}\n")
+	return sb.String()
+}
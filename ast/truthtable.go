@@ -0,0 +1,55 @@
+package ast
+
+import (
+	"sort"
+)
+
+type TruthTableRow struct {
+	Vector string `json:"vector"`
+	Output bool   `json:"output"`
+}
+
+// Evaluate evaluates the AST for the given input values.
+func Evaluate(a *AST, values map[rune]bool) (bool, error) {
+	return evaluate(a, &values)
+}
+
+// MergeInputs returns the sorted union of inputs of all given ASTs.
+func MergeInputs(asts ...*AST) []rune {
+	m := make(map[rune]bool)
+	for _, a := range asts {
+		recursiveBuildInputs(a, &m)
+	}
+	r := make([]rune, 0)
+	for i := range m {
+		r = append(r, i)
+	}
+	sort.Slice(r, func(i, j int) bool {
+		return r[i] < r[j]
+	})
+	return r
+}
+
+// VectorValues maps the input vector (e.g. "0110") to input values.
+func VectorValues(inputs []rune, vector string) map[rune]bool {
+	m := make(map[rune]bool)
+	for i, v := range []rune(vector) {
+		m[inputs[i]] = v != '0'
+	}
+	return m
+}
+
+// TruthTable evaluates the AST on every input vector over the given inputs.
+func TruthTable(a *AST, inputs []rune) ([]TruthTableRow, error) {
+	rows := make([]TruthTableRow, 0)
+	total := int64(1) << len(inputs)
+	for n := int64(0); n < total; n++ {
+		vector := formatVector(n, len(inputs))
+		output, err := Evaluate(a, VectorValues(inputs, vector))
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, TruthTableRow{Vector: vector, Output: output})
+	}
+	return rows, nil
+}
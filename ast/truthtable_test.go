@@ -0,0 +1,36 @@
+package ast
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTruthTable(t *testing.T) {
+	rows, err := TruthTable(mustBuildAST(t, "XOR(A,B)"), []rune("AB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TruthTableRow{{"00", false}, {"01", true}, {"10", true}, {"11", false}}
+	if !slices.Equal(rows, want) {
+		t.Errorf("got %v, want %v", rows, want)
+	}
+}
+
+func TestTruthTableOverMergedInputs(t *testing.T) {
+	a := mustBuildAST(t, "NOT(B)")
+	inputs := MergeInputs(a, mustBuildAST(t, "AND(C,A)"))
+	if string(inputs) != "ABC" {
+		t.Fatalf("got inputs %s, want ABC", string(inputs))
+	}
+	rows, err := TruthTable(a, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 8 || rows[2] != (TruthTableRow{"010", false}) || rows[5] != (TruthTableRow{"101", true}) {
+		t.Errorf("got %v", rows)
+	}
+	// vhod, ki ga ni med podanimi, je napaka
+	if _, err := TruthTable(a, []rune("A")); err == nil {
+		t.Error("evaluation with a missing input was accepted")
+	}
+}
@@ -0,0 +1,89 @@
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RowWeight assigns a weight to all truth table rows matching the pattern. Mandatory rows must be answered
+// correctly, otherwise the partial score is zeroed.
+type RowWeight struct {
+	Pattern   string // '0', '1' or 'X' (any value) for each input, inputs are sorted alphabetically
+	Weight    float64
+	Mandatory bool
+}
+
+// ParseRowWeights parses a semicolon separated list of row weights. Each entry is a pattern, optionally followed by
+// "=WEIGHT" and/or "!" (mandatory), e.g. "11X=5;000!;101=2!".
+func ParseRowWeights(s string) ([]RowWeight, error) {
+	weights := make([]RowWeight, 0)
+	for _, v := range strings.Split(MinifyString(s), ";") {
+		if v == "" {
+			continue
+		}
+		weight := RowWeight{Weight: 1}
+		if strings.HasSuffix(v, "!") {
+			weight.Mandatory = true
+			v = strings.TrimSuffix(v, "!")
+		}
+		pattern, w, found := strings.Cut(v, "=")
+		if found {
+			f, err := strconv.ParseFloat(w, 64)
+			if err != nil || f < 0 {
+				return nil, errors.New(fmt.Sprintf("Row weight %s is invalid. Expected a non-negative number", v))
+			}
+			weight.Weight = f
+		}
+		for _, r := range pattern {
+			if r != '0' && r != '1' && r != 'X' {
+				return nil, errors.New(fmt.Sprintf("Row pattern %s is invalid. Invalid character %s", pattern, string(r)))
+			}
+		}
+		weight.Pattern = pattern
+		weights = append(weights, weight)
+	}
+	return weights, nil
+}
+
+// ValidateRowWeights checks whether all patterns match the given number of inputs.
+func ValidateRowWeights(weights []RowWeight, inputs int) error {
+	for _, v := range weights {
+		if len(v.Pattern) != inputs {
+			return errors.New(fmt.Sprintf("Row pattern %s is invalid. Expected %d inputs, got %d", v.Pattern, inputs, len(v.Pattern)))
+		}
+	}
+	return nil
+}
+
+func (weight RowWeight) Matches(vector string) bool {
+	if len(vector) != len(weight.Pattern) {
+		return false
+	}
+	for i, v := range weight.Pattern {
+		if v != 'X' && byte(v) != vector[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (weight RowWeight) String() string {
+	s := fmt.Sprintf("%s=%g", weight.Pattern, weight.Weight)
+	if weight.Mandatory {
+		s += "!"
+	}
+	return s
+}
+
+// rowWeight returns the weight of the vector, as given by the first matching pattern. Rows without a matching
+// pattern have a weight of 1.
+func rowWeight(weights []RowWeight, vector string) (float64, bool) {
+	for _, v := range weights {
+		if v.Matches(vector) {
+			return v.Weight, v.Mandatory
+		}
+	}
+	return 1, false
+}
@@ -0,0 +1,89 @@
+package ast
+
+import "testing"
+
+func TestParseRowWeights(t *testing.T) {
+	weights, err := ParseRowWeights("11X=5; 000!;101=2!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RowWeight{
+		{Pattern: "11X", Weight: 5},
+		{Pattern: "000", Weight: 1, Mandatory: true},
+		{Pattern: "101", Weight: 2, Mandatory: true},
+	}
+	if len(weights) != len(want) {
+		t.Fatalf("got %v, want %v", weights, want)
+	}
+	for i := range want {
+		if weights[i] != want[i] {
+			t.Errorf("weight %d: got %v, want %v", i, weights[i], want[i])
+		}
+	}
+
+	for _, invalid := range []string{"12X", "11=-1", "11=x"} {
+		if _, err := ParseRowWeights(invalid); err == nil {
+			t.Errorf("%s was accepted", invalid)
+		}
+	}
+	if err := ValidateRowWeights(weights, 4); err == nil {
+		t.Error("patterns of a wrong length were accepted")
+	}
+}
+
+func TestRowWeightFirstMatchApplies(t *testing.T) {
+	weights := []RowWeight{{Pattern: "1X", Weight: 3}, {Pattern: "11", Weight: 7, Mandatory: true}}
+	if weight, mandatory := rowWeight(weights, "11"); weight != 3 || mandatory {
+		t.Errorf("11: got %g %v, want the first pattern", weight, mandatory)
+	}
+	if weight, mandatory := rowWeight(weights, "01"); weight != 1 || mandatory {
+		t.Errorf("01: got %g %v, want the default weight", weight, mandatory)
+	}
+}
+
+func TestWeightedEvaluation(t *testing.T) {
+	solution := mustBuildAST(t, "AND(A,B)")
+	// OR se razlikuje od AND v vrsticah 01 in 10
+	submission := mustBuildAST(t, "OR(A,B)")
+	weights, err := ParseRowWeights("01=3;11=4!")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evaluation, err := TestDigitalSolutionWithOptions(submission, solution, TestOptions{Weights: weights})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 00 (1) in 11 (4) sta pravilni, 01 (3) in 10 (1) napačni
+	if evaluation.CorrectWeight != 5 || evaluation.TotalWeight != 9 {
+		t.Errorf("got weighted correctness %g/%g, want 5/9", evaluation.CorrectWeight, evaluation.TotalWeight)
+	}
+	if len(evaluation.FailedMandatory) != 0 {
+		t.Errorf("got failed mandatory rows %v", evaluation.FailedMandatory)
+	}
+
+	weights[1].Pattern = "10"
+	evaluation, err = TestDigitalSolutionWithOptions(submission, solution, TestOptions{Weights: weights})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evaluation.FailedMandatory) != 1 || evaluation.FailedMandatory[0] != "10" {
+		t.Errorf("got failed mandatory rows %v, want [10]", evaluation.FailedMandatory)
+	}
+}
+
+func TestMandatoryRowsAreSampled(t *testing.T) {
+	solution := mustBuildAST(t, "XOR(XOR(XOR(A,B),XOR(C,D)),XOR(XOR(E,F),XOR(G,H)))")
+	weights, err := ParseRowWeights("10110011!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluation, err := TestDigitalSolutionWithOptions(solution, solution, TestOptions{SampleSize: 4, Weights: weights})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors := testedVectors(evaluation.EvaluationLog)
+	if len(vectors) == 0 || vectors[0] != "10110011" {
+		t.Errorf("mandatory row wasn't tested first: %v", vectors)
+	}
+}
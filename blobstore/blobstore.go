@@ -0,0 +1,98 @@
+package blobstore
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Store keeps the contents of uploaded files. Keys are generated by the caller and must not contain path separators.
+type Store interface {
+	Put(key string, r io.Reader) (int64, error)
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalStore stores every blob as a file in a single directory on the local filesystem.
+type LocalStore struct {
+	directory string
+}
+
+func NewLocalStore(directory string) (*LocalStore, error) {
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStore{directory: directory}, nil
+}
+
+func (store *LocalStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(store.directory, key), nil
+}
+
+// Put writes the blob into a temporary file first, so that a failed upload never leaves a partial blob behind.
+func (store *LocalStore) Put(key string, r io.Reader) (int64, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.CreateTemp(store.directory, ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(file, r)
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return 0, err
+	}
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		os.Remove(file.Name())
+		return 0, err
+	}
+	return size, nil
+}
+
+func (store *LocalStore) Get(key string) (io.ReadCloser, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes the blob. Deleting a missing blob isn't an error.
+func (store *LocalStore) Delete(key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Copy stores the contents of the blob under a new key.
+func Copy(store Store, key string, newKey string) error {
+	r, err := store.Get(key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = store.Put(newKey, r)
+	return err
+}
@@ -0,0 +1,67 @@
+package blobstore
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInvalidKeys(t *testing.T) {
+	directory := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(directory, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ključi ne smejo pobegniti iz mape
+	for _, key := range []string{"", ".", "..", "../x", "a/b", `a\b`, "/etc/passwd", `..\x`} {
+		if _, err := store.Put(key, bytes.NewReader([]byte("x"))); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.Get(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.Delete(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(directory, "x")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("blob was written outside the store")
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := store.Put("key", bytes.NewReader([]byte("content")))
+	if err != nil || size != 7 {
+		t.Fatalf("Put = %d, %v", size, err)
+	}
+	if err := Copy(store, "key", "copy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("key"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("key"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get of a deleted blob = %v", err)
+	}
+	// brisanje manjkajoče datoteke ni napaka
+	if err := store.Delete("key"); err != nil {
+		t.Errorf("Delete of a missing blob = %v", err)
+	}
+
+	r, err := store.Get("copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != "content" {
+		t.Errorf("got copy %q %v", data, err)
+	}
+}
@@ -0,0 +1,429 @@
+package bundle
+
+import (
+	"HTTP-boilerplate/ast"
+	"HTTP-boilerplate/blobstore"
+	"HTTP-boilerplate/db"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"time"
+)
+
+// Version 2 added attachments, version 1 bundles are read without them.
+const (
+	Format  = "logic-competition"
+	Version = 2
+)
+
+// Conflict policies decide what happens, when the imported competition already exists.
+const (
+	ConflictFail    = "fail"    // the import is refused
+	ConflictNew     = "new"     // the competition is imported under new IDs
+	ConflictReplace = "replace" // the existing competition with everything, that belongs to it, is deleted first
+)
+
+var Conflicts = []string{ConflictFail, ConflictNew, ConflictReplace}
+
+// Files in the archive. The manifest comes first, so that readers can check the version before anything else.
+const (
+	manifestFile    = "manifest.json"
+	competitionFile = "competition.json"
+	problemsFile    = "problems.json"
+	teamsFile       = "teams.json"
+	submissionsFile = "submissions.json"
+	attachmentsFile = "attachments.json"
+
+	attachmentsDirectory = "attachments/" // contents of the attachments, named by the attachment's ID
+)
+
+// maxAttachmentSize limits a single attachment in the archive, like uploads are limited.
+const maxAttachmentSize = 20 << 20
+
+// Manifest is the version header of the archive.
+type Manifest struct {
+	Format        string `json:"format"`
+	Version       int    `json:"version"`
+	ExportedAt    int    `json:"exported_at"`
+	CompetitionID string `json:"competition_id"`
+}
+
+// Bundle is a self-contained copy of a competition. Users (authors, team accounts and members) aren't part of it,
+// so the user IDs it refers to are kept only as history.
+type Bundle struct {
+	Manifest    Manifest
+	Competition db.Competition
+	Problems    []db.Problem
+	Teams       []db.Team
+	Submissions []db.Submission // all revisions, superseded ones included
+	Attachments []db.Attachment
+	Files       map[string][]byte // contents of the attachments by the attachment's ID
+}
+
+// Options of the import.
+type Options struct {
+	Conflict string
+	DryRun   bool
+}
+
+// Report describes the (possibly only planned) result of an import.
+type Report struct {
+	CompetitionID string   `json:"competition_id"`
+	DryRun        bool     `json:"dry_run"`
+	Conflict      bool     `json:"conflict"` // whether the competition already existed
+	Remapped      bool     `json:"remapped"` // whether new IDs were given
+	Problems      int      `json:"problems"`
+	Teams         int      `json:"teams"`
+	Submissions   int      `json:"submissions"`
+	Attachments   int      `json:"attachments"`
+	Errors        []string `json:"errors"`
+}
+
+// Export reads the competition with everything that belongs to it from the database and the blob store.
+func Export(database db.SQL, blobs blobstore.Store, competitionId string) (bundle Bundle, err error) {
+	bundle.Competition, err = database.GetCompetition(competitionId)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Problems, err = database.GetProblemsForCompetition(competitionId)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Teams, err = database.GetTeamsForCompetition(competitionId)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Submissions, err = database.GetSubmissionHistoryForCompetition(competitionId)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Attachments, err = database.GetAttachmentsForCompetition(competitionId)
+	if err != nil {
+		return bundle, err
+	}
+	bundle.Files = make(map[string][]byte)
+	for _, v := range bundle.Attachments {
+		r, err := blobs.Get(v.BlobKey)
+		if err != nil {
+			return bundle, err
+		}
+		bundle.Files[v.ID], err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return bundle, err
+		}
+	}
+	bundle.Manifest = Manifest{
+		Format:        Format,
+		Version:       Version,
+		ExportedAt:    int(time.Now().Unix()),
+		CompetitionID: competitionId,
+	}
+	return bundle, nil
+}
+
+// Write writes the bundle as a zip archive.
+func (bundle Bundle) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{manifestFile, bundle.Manifest},
+		{competitionFile, bundle.Competition},
+		{problemsFile, bundle.Problems},
+		{teamsFile, bundle.Teams},
+		{submissionsFile, bundle.Submissions},
+		{attachmentsFile, bundle.Attachments},
+	}
+	for _, v := range files {
+		file, err := archive.Create(v.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(v.data)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range bundle.Attachments {
+		file, err := archive.Create(attachmentsDirectory + v.ID)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(bundle.Files[v.ID])
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func readFile(archive *zip.Reader, name string, v any) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return errors.New(fmt.Sprintf("missing %s", name))
+	}
+	defer file.Close()
+	err = json.NewDecoder(file).Decode(v)
+	if err != nil {
+		return errors.New(fmt.Sprintf("invalid %s: %s", name, err.Error()))
+	}
+	return nil
+}
+
+// Read reads a zip archive, written by Write.
+func Read(r io.ReaderAt, size int64) (bundle Bundle, err error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return bundle, err
+	}
+	err = readFile(archive, manifestFile, &bundle.Manifest)
+	if err != nil {
+		return bundle, err
+	}
+	if bundle.Manifest.Format != Format {
+		return bundle, errors.New("the archive isn't a competition bundle")
+	}
+	if bundle.Manifest.Version < 1 || bundle.Manifest.Version > Version {
+		return bundle, errors.New(fmt.Sprintf("unsupported bundle version %d, expected at most %d", bundle.Manifest.Version, Version))
+	}
+	err = readFile(archive, competitionFile, &bundle.Competition)
+	if err != nil {
+		return bundle, err
+	}
+	err = readFile(archive, problemsFile, &bundle.Problems)
+	if err != nil {
+		return bundle, err
+	}
+	err = readFile(archive, teamsFile, &bundle.Teams)
+	if err != nil {
+		return bundle, err
+	}
+	err = readFile(archive, submissionsFile, &bundle.Submissions)
+	if err != nil {
+		return bundle, err
+	}
+
+	bundle.Files = make(map[string][]byte)
+	if bundle.Manifest.Version < 2 {
+		return bundle, nil
+	}
+	err = readFile(archive, attachmentsFile, &bundle.Attachments)
+	if err != nil {
+		return bundle, err
+	}
+	for _, v := range bundle.Attachments {
+		file, err := archive.Open(attachmentsDirectory + v.ID)
+		if err != nil {
+			return bundle, errors.New(fmt.Sprintf("missing the contents of attachment %s", v.ID))
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxAttachmentSize+1))
+		file.Close()
+		if err != nil {
+			return bundle, errors.New(fmt.Sprintf("invalid contents of attachment %s: %s", v.ID, err.Error()))
+		}
+		if len(data) > maxAttachmentSize {
+			return bundle, errors.New(fmt.Sprintf("attachment %s is too large", v.ID))
+		}
+		bundle.Files[v.ID] = data
+	}
+	return bundle, nil
+}
+
+// Validate checks, that every reference solution compiles and that all references inside the bundle are valid.
+func (bundle Bundle) Validate() []string {
+	problems := make(map[string]bool)
+	teams := make(map[string]bool)
+	submissions := make(map[string]bool)
+	result := make([]string, 0)
+
+	if bundle.Competition.ID == "" || bundle.Competition.Name == "" {
+		result = append(result, "competition is missing its ID or name")
+	}
+	for _, v := range bundle.Problems {
+		if problems[v.ID] {
+			result = append(result, fmt.Sprintf("problem %s is given more than once", v.ID))
+		}
+		problems[v.ID] = true
+		for _, solution := range v.ReferenceSolutions() {
+			if _, err := ast.BuildAST(solution); err != nil {
+				result = append(result, fmt.Sprintf("problem %s (%s): solution %s doesn't compile: %s", v.ID, v.Name, solution, err.Error()))
+			}
+		}
+	}
+	for _, v := range bundle.Teams {
+		if teams[v.ID] {
+			result = append(result, fmt.Sprintf("team %s is given more than once", v.ID))
+		}
+		teams[v.ID] = true
+	}
+	attachments := make(map[string]bool)
+	for _, v := range bundle.Attachments {
+		if attachments[v.ID] {
+			result = append(result, fmt.Sprintf("attachment %s is given more than once", v.ID))
+		}
+		attachments[v.ID] = true
+		if !problems[v.ProblemID] {
+			result = append(result, fmt.Sprintf("attachment %s refers to an unknown problem %s", v.ID, v.ProblemID))
+		}
+	}
+	for _, v := range bundle.Submissions {
+		submissions[v.ID] = true
+	}
+	for _, v := range bundle.Submissions {
+		if !problems[v.ProblemID] {
+			result = append(result, fmt.Sprintf("submission %s refers to an unknown problem %s", v.ID, v.ProblemID))
+		}
+		if !teams[v.TeamID] {
+			result = append(result, fmt.Sprintf("submission %s refers to an unknown team %s", v.ID, v.TeamID))
+		}
+		if v.Supersedes != "" && !submissions[v.Supersedes] {
+			result = append(result, fmt.Sprintf("submission %s supersedes an unknown submission %s", v.ID, v.Supersedes))
+		}
+	}
+	return result
+}
+
+// remap gives new IDs to everything in the bundle and updates the references.
+func (bundle *Bundle) remap() {
+	ids := make(map[string]string)
+	newId := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		if _, ok := ids[id]; !ok {
+			ids[id] = uuid.NewString()
+		}
+		return ids[id]
+	}
+
+	bundle.Competition.ID = newId(bundle.Competition.ID)
+	for i := range bundle.Problems {
+		bundle.Problems[i].ID = newId(bundle.Problems[i].ID)
+		bundle.Problems[i].CompetitionID = bundle.Competition.ID
+	}
+	for i := range bundle.Teams {
+		bundle.Teams[i].ID = newId(bundle.Teams[i].ID)
+		bundle.Teams[i].CompetitionID = bundle.Competition.ID
+	}
+	for i := range bundle.Submissions {
+		bundle.Submissions[i].ID = newId(bundle.Submissions[i].ID)
+		bundle.Submissions[i].CompetitionID = bundle.Competition.ID
+		bundle.Submissions[i].ProblemID = newId(bundle.Submissions[i].ProblemID)
+		bundle.Submissions[i].TeamID = newId(bundle.Submissions[i].TeamID)
+		bundle.Submissions[i].Supersedes = newId(bundle.Submissions[i].Supersedes)
+	}
+	files := make(map[string][]byte)
+	for i := range bundle.Attachments {
+		id := newId(bundle.Attachments[i].ID)
+		files[id] = bundle.Files[bundle.Attachments[i].ID]
+		bundle.Attachments[i].ID = id
+		bundle.Attachments[i].CompetitionID = bundle.Competition.ID
+		bundle.Attachments[i].ProblemID = newId(bundle.Attachments[i].ProblemID)
+	}
+	bundle.Files = files
+}
+
+// adopt assigns everything in the bundle to the bundle's competition, so that a bundle can't write into other
+// competitions, whatever IDs it claims.
+func (bundle *Bundle) adopt() {
+	for i := range bundle.Problems {
+		bundle.Problems[i].CompetitionID = bundle.Competition.ID
+	}
+	for i := range bundle.Teams {
+		bundle.Teams[i].CompetitionID = bundle.Competition.ID
+	}
+	for i := range bundle.Submissions {
+		bundle.Submissions[i].CompetitionID = bundle.Competition.ID
+	}
+	for i := range bundle.Attachments {
+		bundle.Attachments[i].CompetitionID = bundle.Competition.ID
+	}
+}
+
+// Import validates the bundle and, unless it is a dry run, writes it into the database in a single transaction.
+// Competitions, which haven't finished yet, are imported as drafts. Pending submissions are imported along with
+// judging jobs, so that the judging queue picks them up.
+func Import(database db.SQL, blobs blobstore.Store, bundle Bundle, options Options) (Report, error) {
+	report := Report{
+		CompetitionID: bundle.Competition.ID,
+		DryRun:        options.DryRun,
+		Problems:      len(bundle.Problems),
+		Teams:         len(bundle.Teams),
+		Submissions:   len(bundle.Submissions),
+		Attachments:   len(bundle.Attachments),
+		Errors:        bundle.Validate(),
+	}
+
+	if options.Conflict == "" {
+		options.Conflict = ConflictFail
+	}
+	_, err := database.GetCompetition(bundle.Competition.ID)
+	report.Conflict = err == nil
+	if report.Conflict {
+		switch options.Conflict {
+		case ConflictFail:
+			report.Errors = append(report.Errors, fmt.Sprintf("competition %s already exists", bundle.Competition.ID))
+		case ConflictNew:
+			bundle.remap()
+			report.Remapped = true
+			report.CompetitionID = bundle.Competition.ID
+		case ConflictReplace:
+		default:
+			report.Errors = append(report.Errors, fmt.Sprintf("unknown conflict policy %s", options.Conflict))
+		}
+	}
+
+	if len(report.Errors) != 0 || options.DryRun {
+		return report, nil
+	}
+	bundle.adopt()
+
+	// uvoženo tekmovanje se začne kot osnutek, razen če je že končano
+	competition := bundle.Competition
+	if competition.Status != db.StatusFinished && competition.Status != db.StatusArchived {
+		competition.Status = db.StatusDraft
+	}
+	// vsebina priponk dobi nove ključe, ker zamenjano tekmovanje po uvozu izbriše svoje
+	attachments := make([]db.Attachment, len(bundle.Attachments))
+	removeBlobs := func(attachments []db.Attachment) {
+		for _, v := range attachments {
+			blobs.Delete(v.BlobKey)
+		}
+	}
+	for i, v := range bundle.Attachments {
+		v.BlobKey = uuid.NewString()
+		v.Size = len(bundle.Files[v.ID])
+		_, err = blobs.Put(v.BlobKey, bytes.NewReader(bundle.Files[v.ID]))
+		if err != nil {
+			removeBlobs(attachments[:i])
+			return report, err
+		}
+		attachments[i] = v
+	}
+
+	jobs := make([]db.JudgingJob, 0)
+	for _, v := range bundle.Submissions {
+		if v.Verdict == "PENDING" && !v.Superseded {
+			jobs = append(jobs, db.JudgingJob{ID: uuid.NewString(), SubmissionID: v.ID, Status: db.JobQueued})
+		}
+	}
+
+	blobKeys, err := database.ImportCompetition(competition, bundle.Problems, attachments, bundle.Teams, bundle.Submissions, jobs, report.Conflict && options.Conflict == ConflictReplace)
+	if err != nil {
+		removeBlobs(attachments)
+		return report, err
+	}
+	// priponke zamenjanega tekmovanja so izbrisane šele po uspešnem uvozu, neizbrisana datoteka le zaseda prostor
+	for _, v := range blobKeys {
+		blobs.Delete(v)
+	}
+	return report, nil
+}
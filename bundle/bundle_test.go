@@ -0,0 +1,276 @@
+package bundle
+
+import (
+	"HTTP-boilerplate/blobstore"
+	"HTTP-boilerplate/db"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// newTestDatabase returns a fresh SQLite database, with the schema and all migrations applied.
+func newTestDatabase(t *testing.T) db.SQL {
+	t.Helper()
+	database, err := db.NewSQL("sqlite3", filepath.Join(t.TempDir(), "database.sqlite3"), zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.Init()
+	migrations, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range migrations {
+		query, err := os.ReadFile(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := database.Exec(string(query)); err != nil {
+			t.Fatalf("migration %s: %v", v, err)
+		}
+	}
+	return database
+}
+
+func newTestStore(t *testing.T) blobstore.Store {
+	t.Helper()
+	store, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// seedCompetition inserts a finished competition with a problem, its attachment, a team, a judged and a pending
+// submission.
+func seedCompetition(t *testing.T, database db.SQL, blobs blobstore.Store) {
+	t.Helper()
+	err := database.InsertCompetition(db.Competition{ID: "competition", Name: "Competition", Status: db.StatusFinished, PenaltyEach: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = database.InsertProblem(db.Problem{ID: "problem", Name: "Problem", CompetitionID: "competition", Solution: "AND(A,B)", Points: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blobs.Put("blob", bytes.NewReader([]byte("circuit"))); err != nil {
+		t.Fatal(err)
+	}
+	err = database.InsertAttachment(db.Attachment{ID: "attachment", ProblemID: "problem", CompetitionID: "competition", Name: "circuit.txt", ContentType: "text/plain", Size: 7, BlobKey: "blob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = database.InsertTeam(db.Team{ID: "team", Name: "Team", CompetitionID: "competition"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []db.Submission{
+		{ID: "judged", Solution: "AND(A,B)", Verdict: "AC", Score: 100, CompetitionID: "competition", ProblemID: "problem", TeamID: "team", Public: true},
+		{ID: "pending", Solution: "OR(A,B)", Verdict: "PENDING", CompetitionID: "competition", ProblemID: "problem", TeamID: "team", SubmittedSeconds: 60},
+	} {
+		if err := database.InsertSubmission(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// roundTrip exports the competition and reads it back from the archive.
+func roundTrip(t *testing.T, database db.SQL, blobs blobstore.Store) Bundle {
+	t.Helper()
+	exported, err := Export(database, blobs, "competition")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buffer bytes.Buffer
+	if err := exported.Write(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := Read(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bundle
+}
+
+func TestRoundTrip(t *testing.T) {
+	source := newTestDatabase(t)
+	sourceBlobs := newTestStore(t)
+	seedCompetition(t, source, sourceBlobs)
+	bundle := roundTrip(t, source, sourceBlobs)
+
+	target := newTestDatabase(t)
+	targetBlobs := newTestStore(t)
+	report, err := Import(target, targetBlobs, bundle, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Errors) != 0 || report.Conflict || report.Problems != 1 || report.Teams != 1 || report.Submissions != 2 || report.Attachments != 1 {
+		t.Fatalf("got report %+v", report)
+	}
+
+	competition, err := target.GetCompetition("competition")
+	if err != nil || competition.Name != "Competition" || competition.Status != db.StatusFinished {
+		t.Fatalf("got competition %+v %v", competition, err)
+	}
+	submission, err := target.GetSubmission("judged")
+	if err != nil || submission.Verdict != "AC" || submission.Score != 100 || !submission.Public {
+		t.Fatalf("got submission %+v %v", submission, err)
+	}
+	// oddaje, ki še niso bile ocenjene, dobijo opravilo
+	jobs, err := target.GetQueuedJudgingJobs(10)
+	if err != nil || len(jobs) != 1 || jobs[0].SubmissionID != "pending" {
+		t.Fatalf("got judging jobs %+v %v", jobs, err)
+	}
+	attachment, err := target.GetAttachment("attachment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := targetBlobs.Get(attachment.BlobKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil || string(data) != "circuit" || attachment.Size != 7 {
+		t.Errorf("got attachment %+v with content %q %v", attachment, data, err)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	database := newTestDatabase(t)
+	blobs := newTestStore(t)
+	seedCompetition(t, database, blobs)
+	bundle := roundTrip(t, database, blobs)
+
+	report, err := Import(database, blobs, bundle, Options{Conflict: ConflictFail})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Conflict || len(report.Errors) == 0 {
+		t.Errorf("conflict fail: got report %+v", report)
+	}
+
+	report, err = Import(database, blobs, roundTrip(t, database, blobs), Options{Conflict: ConflictNew})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Remapped || report.CompetitionID == "competition" || len(report.Errors) != 0 {
+		t.Fatalf("conflict new: got report %+v", report)
+	}
+	teams, err := database.GetTeamsForCompetition(report.CompetitionID)
+	if err != nil || len(teams) != 1 || teams[0].ID == "team" {
+		t.Fatalf("conflict new: got teams %+v %v", teams, err)
+	}
+	submissions, err := database.GetSubmissionsForCompetition(report.CompetitionID)
+	if err != nil || len(submissions) != 2 || submissions[0].TeamID != teams[0].ID {
+		t.Fatalf("conflict new: got submissions %+v %v", submissions, err)
+	}
+
+	bundle.Competition.Name = "Replaced"
+	bundle.Submissions = bundle.Submissions[:1]
+	report, err = Import(database, blobs, bundle, Options{Conflict: ConflictReplace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Conflict || len(report.Errors) != 0 {
+		t.Fatalf("conflict replace: got report %+v", report)
+	}
+	competition, err := database.GetCompetition("competition")
+	if err != nil || competition.Name != "Replaced" {
+		t.Fatalf("conflict replace: got competition %+v %v", competition, err)
+	}
+	if _, err := database.GetSubmission("pending"); err == nil {
+		t.Error("conflict replace: submission, which isn't in the bundle, was kept")
+	}
+
+	// dry run ne spremeni ničesar
+	bundle.Competition.Name = "Dry"
+	report, err = Import(database, blobs, bundle, Options{Conflict: ConflictReplace, DryRun: true})
+	if err != nil || !report.DryRun {
+		t.Fatalf("dry run: got report %+v %v", report, err)
+	}
+	if competition, _ := database.GetCompetition("competition"); competition.Name != "Replaced" {
+		t.Error("dry run changed the competition")
+	}
+}
+
+func TestImportStaysInsideTheCompetition(t *testing.T) {
+	database := newTestDatabase(t)
+	blobs := newTestStore(t)
+	seedCompetition(t, database, blobs)
+	if err := database.InsertCompetition(db.Competition{ID: "victim", Name: "Victim", PenaltyEach: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := roundTrip(t, database, blobs)
+	for i := range bundle.Problems {
+		bundle.Problems[i].CompetitionID = "victim"
+	}
+	for i := range bundle.Teams {
+		bundle.Teams[i].CompetitionID = "victim"
+	}
+	for i := range bundle.Submissions {
+		bundle.Submissions[i].CompetitionID = "victim"
+	}
+	for i := range bundle.Attachments {
+		bundle.Attachments[i].CompetitionID = "victim"
+	}
+	report, err := Import(database, blobs, bundle, Options{Conflict: ConflictReplace})
+	if err != nil || len(report.Errors) != 0 {
+		t.Fatalf("got report %+v %v", report, err)
+	}
+	problems, err := database.GetProblemsForCompetition("victim")
+	if err != nil || len(problems) != 0 {
+		t.Errorf("bundle wrote problems %+v into another competition", problems)
+	}
+	teams, err := database.GetTeamsForCompetition("victim")
+	if err != nil || len(teams) != 0 {
+		t.Errorf("bundle wrote teams %+v into another competition", teams)
+	}
+	submission, err := database.GetSubmission("judged")
+	if err != nil || submission.CompetitionID != "competition" {
+		t.Errorf("got submission %+v %v", submission, err)
+	}
+	attachments, err := database.GetAttachmentsForCompetition("victim")
+	if err != nil || len(attachments) != 0 {
+		t.Errorf("bundle wrote attachments %+v into another competition", attachments)
+	}
+
+	// sklici izven svežnja so zavrnjeni
+	bundle.Submissions[0].TeamID = "foreign"
+	report, err = Import(database, blobs, bundle, Options{Conflict: ConflictReplace})
+	if err != nil || len(report.Errors) == 0 {
+		t.Errorf("reference outside the bundle: got report %+v %v", report, err)
+	}
+}
+
+func TestDeleteCompetition(t *testing.T) {
+	database := newTestDatabase(t)
+	blobs := newTestStore(t)
+	seedCompetition(t, database, blobs)
+	bundle := roundTrip(t, database, blobs)
+
+	blobKeys, err := database.DeleteCompetition("competition")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobKeys) != 1 || blobKeys[0] != "blob" {
+		t.Errorf("got blob keys %v", blobKeys)
+	}
+	if _, err := database.GetSubmission("judged"); err == nil {
+		t.Error("submission of the deleted competition was kept")
+	}
+	if problems, _ := database.GetProblemsForCompetition("competition"); len(problems) != 0 {
+		t.Error("problems of the deleted competition were kept")
+	}
+
+	// ponoven uvoz z istimi ID-ji uspe
+	report, err := Import(database, blobs, bundle, Options{})
+	if err != nil || len(report.Errors) != 0 || report.Conflict {
+		t.Fatalf("re-import: got report %+v %v", report, err)
+	}
+}
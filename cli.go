@@ -0,0 +1,111 @@
+package main
+
+import (
+	"HTTP-boilerplate/blobstore"
+	"HTTP-boilerplate/bundle"
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Commands move competitions between servers, e.g. from a staging SQLite database into production Postgres:
+//
+//	backend export -competition <id> -out competition.zip
+//	backend import -in competition.zip -conflict new -dry-run
+func runCommand(database db.SQL, blobs blobstore.Store, args []string) int {
+	switch args[0] {
+	case "export":
+		return exportCommand(database, blobs, args[1:])
+	case "import":
+		return importCommand(database, blobs, args[1:])
+	}
+	fmt.Fprintln(os.Stderr, "Usage: backend [export|import] [flags]")
+	fmt.Fprintln(os.Stderr, "Without a command, the server is started.")
+	return 2
+}
+
+func exportCommand(database db.SQL, blobs blobstore.Store, args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	competitionId := flags.String("competition", "", "ID of the competition")
+	out := flags.String("out", "", "path of the bundle to write")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *competitionId == "" || *out == "" {
+		fmt.Fprintln(os.Stderr, "-competition and -out are required")
+		return 2
+	}
+
+	b, err := bundle.Export(database, blobs, *competitionId)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to export the competition:", err.Error())
+		return 1
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to create the bundle:", err.Error())
+		return 1
+	}
+	defer file.Close()
+
+	err = b.Write(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to write the bundle:", err.Error())
+		return 1
+	}
+	fmt.Printf("Exported %s with %d problems, %d teams and %d submissions\n", b.Competition.Name, len(b.Problems), len(b.Teams), len(b.Submissions))
+	return 0
+}
+
+func importCommand(database db.SQL, blobs blobstore.Store, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	in := flags.String("in", "", "path of the bundle to import")
+	conflict := flags.String("conflict", bundle.ConflictFail, "what to do if the competition already exists: fail, new or replace")
+	dryRun := flags.Bool("dry-run", false, "only validate the bundle")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "-in is required")
+		return 2
+	}
+	if !slices.Contains(bundle.Conflicts, *conflict) {
+		fmt.Fprintln(os.Stderr, "-conflict has to be fail, new or replace")
+		return 2
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open the bundle:", err.Error())
+		return 1
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open the bundle:", err.Error())
+		return 1
+	}
+
+	b, err := bundle.Read(file, stat.Size())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid bundle:", err.Error())
+		return 1
+	}
+
+	report, err := bundle.Import(database, blobs, b, bundle.Options{Conflict: *conflict, DryRun: *dryRun})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to import the competition:", err.Error())
+		return 1
+	}
+
+	marshal, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(marshal))
+	if len(report.Errors) != 0 {
+		return 1
+	}
+	return 0
+}
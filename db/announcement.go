@@ -0,0 +1,39 @@
+package db
+
+import "time"
+
+// Announcement is a message of the judges to all teams, e.g. a correction of a problem.
+type Announcement struct {
+	ID            string
+	CompetitionID string `db:"competition_id"`
+	ProblemID     string `db:"problem_id"` // empty if the announcement concerns the whole competition
+	Text          string
+	AuthorID      string `db:"author_id"`
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+func (db *sqlImpl) GetAnnouncement(id string) (announcement Announcement, err error) {
+	err = db.db.Get(&announcement, "SELECT * FROM announcements WHERE id=$1", id)
+	return announcement, err
+}
+
+func (db *sqlImpl) InsertAnnouncement(announcement Announcement) (err error) {
+	announcement.CreatedAt = int(time.Now().Unix())
+	announcement.UpdatedAt = announcement.CreatedAt
+	_, err = db.db.NamedExec(
+		`INSERT INTO announcements (id, competition_id, problem_id, text, author_id, created_at, updated_at) VALUES (:id, :competition_id, :problem_id, :text, :author_id, :created_at, :updated_at)`,
+		announcement)
+	return err
+}
+
+func (db *sqlImpl) GetAnnouncementsForCompetition(competitionId string) (announcements []Announcement, err error) {
+	err = db.db.Select(&announcements, "SELECT * FROM announcements WHERE competition_id=$1 ORDER BY created_at ASC", competitionId)
+	return announcements, err
+}
+
+func (db *sqlImpl) DeleteAnnouncement(id string) error {
+	_, err := db.db.Exec("DELETE FROM announcements WHERE id=$1", id)
+	return err
+}
@@ -0,0 +1,48 @@
+package db
+
+import "time"
+
+// Attachment is a file (e.g. an image of a circuit or a PDF) attached to a problem. Its content lives in the blob
+// store under BlobKey.
+type Attachment struct {
+	ID            string
+	ProblemID     string `db:"problem_id"`
+	CompetitionID string `db:"competition_id"`
+	Name          string // original file name
+	ContentType   string `db:"content_type"`
+	Size          int    // bytes
+	BlobKey       string `db:"blob_key"`
+	UploadedBy    string `db:"uploaded_by"`
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+func (db *sqlImpl) GetAttachment(id string) (attachment Attachment, err error) {
+	err = db.db.Get(&attachment, "SELECT * FROM attachments WHERE id=$1", id)
+	return attachment, err
+}
+
+func (db *sqlImpl) GetAttachmentsForProblem(problemId string) (attachments []Attachment, err error) {
+	err = db.db.Select(&attachments, "SELECT * FROM attachments WHERE problem_id=$1 ORDER BY created_at ASC", problemId)
+	return attachments, err
+}
+
+func (db *sqlImpl) GetAttachmentsForCompetition(competitionId string) (attachments []Attachment, err error) {
+	err = db.db.Select(&attachments, "SELECT * FROM attachments WHERE competition_id=$1 ORDER BY created_at ASC", competitionId)
+	return attachments, err
+}
+
+const insertAttachmentQuery = `INSERT INTO attachments (id, problem_id, competition_id, name, content_type, size, blob_key, uploaded_by, created_at, updated_at) VALUES (:id, :problem_id, :competition_id, :name, :content_type, :size, :blob_key, :uploaded_by, :created_at, :updated_at)`
+
+func (db *sqlImpl) InsertAttachment(attachment Attachment) (err error) {
+	attachment.CreatedAt = int(time.Now().Unix())
+	attachment.UpdatedAt = attachment.CreatedAt
+	_, err = db.db.NamedExec(insertAttachmentQuery, attachment)
+	return err
+}
+
+func (db *sqlImpl) DeleteAttachment(id string) error {
+	_, err := db.db.Exec("DELETE FROM attachments WHERE id=$1", id)
+	return err
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AuditEntry records a single administrative action. Before and After are JSON snapshots of the entity, empty
+// when the entity didn't exist before or after the action.
+type AuditEntry struct {
+	ID            string
+	UserID        string `db:"user_id"`
+	Username      string
+	Action        string
+	EntityType    string `db:"entity_type"`
+	EntityID      string `db:"entity_id"`
+	CompetitionID string `db:"competition_id"`
+	Before        string `db:"snapshot_before"`
+	After         string `db:"snapshot_after"`
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+// AuditFilter selects audit entries. Empty fields aren't filtered on.
+type AuditFilter struct {
+	UserID        string
+	Action        string
+	EntityType    string
+	EntityID      string
+	CompetitionID string
+	Since         int // unix time, inclusive
+	Until         int // unix time, exclusive
+	Limit         int
+	Offset        int
+}
+
+func (db *sqlImpl) InsertAuditEntry(entry AuditEntry) (err error) {
+	entry.CreatedAt = int(time.Now().Unix())
+	entry.UpdatedAt = entry.CreatedAt
+	_, err = db.db.NamedExec(
+		`INSERT INTO audit_log (id, user_id, username, action, entity_type, entity_id, competition_id, snapshot_before, snapshot_after, created_at, updated_at) VALUES
+(:id, :user_id, :username, :action, :entity_type, :entity_id, :competition_id, :snapshot_before, :snapshot_after, :created_at, :updated_at)`,
+		entry)
+	return err
+}
+
+// GetAuditLog returns the filtered entries, newest first, along with the total number of matching entries.
+func (db *sqlImpl) GetAuditLog(filter AuditFilter) (entries []AuditEntry, total int, err error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != "" {
+		add("user_id=$%d", filter.UserID)
+	}
+	if filter.Action != "" {
+		add("action=$%d", filter.Action)
+	}
+	if filter.EntityType != "" {
+		add("entity_type=$%d", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		add("entity_id=$%d", filter.EntityID)
+	}
+	if filter.CompetitionID != "" {
+		add("competition_id=$%d", filter.CompetitionID)
+	}
+	if filter.Since != 0 {
+		add("created_at >= $%d", filter.Since)
+	}
+	if filter.Until != 0 {
+		add("created_at < $%d", filter.Until)
+	}
+
+	where := ""
+	if len(conditions) != 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	err = db.db.Get(&total, "SELECT COUNT(*) FROM audit_log"+where, args...)
+	if err != nil {
+		return entries, total, err
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	err = db.db.Select(&entries, fmt.Sprintf("SELECT * FROM audit_log%s ORDER BY created_at DESC, id ASC LIMIT $%d OFFSET $%d", where, len(args)-1, len(args)), args...)
+	return entries, total, err
+}
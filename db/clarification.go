@@ -0,0 +1,59 @@
+package db
+
+import "time"
+
+// Clarification is a question of a team about a problem or, if ProblemID is empty, about the whole competition.
+// Public clarifications are answered to all teams.
+type Clarification struct {
+	ID            string
+	CompetitionID string `db:"competition_id"`
+	ProblemID     string `db:"problem_id"`
+	TeamID        string `db:"team_id"`
+	Question      string
+	Answer        string
+	AskedBy       string `db:"asked_by"`    // ID of the user, who asked the question (a team or a judge on its behalf)
+	AnsweredBy    string `db:"answered_by"` // ID of the judge, empty while the question is unanswered
+	Public        bool
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+func (db *sqlImpl) GetClarification(id string) (clarification Clarification, err error) {
+	err = db.db.Get(&clarification, "SELECT * FROM clarifications WHERE id=$1", id)
+	return clarification, err
+}
+
+func (db *sqlImpl) InsertClarification(clarification Clarification) (err error) {
+	clarification.CreatedAt = int(time.Now().Unix())
+	clarification.UpdatedAt = clarification.CreatedAt
+	_, err = db.db.NamedExec(
+		`INSERT INTO clarifications (id, competition_id, problem_id, team_id, question, answer, asked_by, answered_by, public, created_at, updated_at) VALUES
+(:id, :competition_id, :problem_id, :team_id, :question, :answer, :asked_by, :answered_by, :public, :created_at, :updated_at)`,
+		clarification)
+	return err
+}
+
+func (db *sqlImpl) GetClarificationsForCompetition(competitionId string) (clarifications []Clarification, err error) {
+	err = db.db.Select(&clarifications, "SELECT * FROM clarifications WHERE competition_id=$1 ORDER BY created_at ASC", competitionId)
+	return clarifications, err
+}
+
+// GetTeamClarifications returns the team's own clarifications along with all public ones.
+func (db *sqlImpl) GetTeamClarifications(competitionId string, teamId string) (clarifications []Clarification, err error) {
+	err = db.db.Select(&clarifications, "SELECT * FROM clarifications WHERE competition_id=$1 AND (team_id=$2 OR public=true) ORDER BY created_at ASC", competitionId, teamId)
+	return clarifications, err
+}
+
+func (db *sqlImpl) UpdateClarification(clarification Clarification) error {
+	clarification.UpdatedAt = int(time.Now().Unix())
+	_, err := db.db.NamedExec(
+		"UPDATE clarifications SET problem_id=:problem_id, question=:question, answer=:answer, answered_by=:answered_by, public=:public, updated_at=:updated_at WHERE id=:id",
+		clarification)
+	return err
+}
+
+func (db *sqlImpl) DeleteClarification(id string) error {
+	_, err := db.db.Exec("DELETE FROM clarifications WHERE id=$1", id)
+	return err
+}
@@ -1,16 +1,39 @@
 package db
 
 import (
+	"github.com/jmoiron/sqlx"
 	"time"
 )
 
+// Competition states. The original states (draft, running, finished) keep their values.
+const (
+	StatusDraft     = 0
+	StatusRunning   = 1
+	StatusFinished  = 2
+	StatusScheduled = 3
+	StatusPaused    = 4
+	StatusArchived  = 5
+)
+
 type Competition struct {
-	ID          string
-	Name        string
-	Status      int
-	StartTime   int `db:"start_time"`
-	Penalty     int `db:"penalty"`      // time penalty
-	PenaltyEach int `db:"penalty_each"` // per how many minutes a penalty should be given
+	ID                string
+	Name              string
+	Status            int
+	StartTime         int    `db:"start_time"`
+	Penalty           int    `db:"penalty"`      // time penalty
+	PenaltyEach       int    `db:"penalty_each"` // per how many minutes a penalty should be given
+	ScoringPolicy     string `db:"scoring_policy"`
+	ScoringParameters string `db:"scoring_parameters"` // JSON encoded scoring.Parameters, empty for defaults
+	PublicScoreboard  bool   `db:"public_scoreboard"`  // whether the scoreboard is available without logging in
+	FreezeTime        int    `db:"freeze_time"`        // minutes after the start, from which on results are hidden from public scoreboards. 0 if there is no freeze
+	TieBreakers       string `db:"tie_breakers"`       // comma separated tie-breakers in order of importance, empty for defaults
+	PlannedStart      int    `db:"planned_start"`      // unix time, at which a scheduled competition is started
+	PlannedEnd        int    `db:"planned_end"`        // unix time, at which the competition is finished. 0 if it isn't planned
+	Duration          int    `db:"duration"`           // minutes of contest time, after which the competition is finished. 0 if unlimited
+	EndTime           int    `db:"end_time"`           // unix time, at which the competition was finished
+	PausedAt          int    `db:"paused_at"`          // unix time of the current pause, 0 if the competition isn't paused
+	PausedTime        int    `db:"paused_time"`        // seconds spent in finished pauses, excluded from contest time
+	IsTemplate        bool   `db:"is_template"`        // templates are only cloned into new competitions and never run
 
 	CreatedAt int `db:"created_at"`
 	UpdatedAt int `db:"updated_at"`
@@ -21,29 +44,220 @@ func (db *sqlImpl) GetCompetition(id string) (competition Competition, err error
 	return competition, err
 }
 
+const insertCompetitionQuery = `INSERT INTO competitions (id, name, status, start_time, penalty, penalty_each, scoring_policy, scoring_parameters, public_scoreboard, freeze_time, tie_breakers, planned_start, planned_end, duration, end_time, paused_at, paused_time, is_template, created_at, updated_at) VALUES (:id, :name, :status, :start_time, :penalty, :penalty_each, :scoring_policy, :scoring_parameters, :public_scoreboard, :freeze_time, :tie_breakers, :planned_start, :planned_end, :duration, :end_time, :paused_at, :paused_time, :is_template, :created_at, :updated_at)`
+
 func (db *sqlImpl) InsertCompetition(competition Competition) (err error) {
 	competition.CreatedAt = int(time.Now().Unix())
 	competition.UpdatedAt = competition.CreatedAt
-	_, err = db.db.NamedExec(
-		`INSERT INTO competitions (id, name, status, start_time, penalty, penalty_each, created_at, updated_at) VALUES (:id, :name, :status, :start_time, :penalty, :penalty_each, :created_at, :updated_at)`,
-		competition)
+	_, err = db.db.NamedExec(insertCompetitionQuery, competition)
 	return err
 }
 
+// InsertCompetitionCopy inserts the competition with its problems, attachments, teams and members in a single
+// transaction, so that a failed copy leaves nothing behind.
+func (db *sqlImpl) InsertCompetitionCopy(competition Competition, problems []Problem, attachments []Attachment, teams []Team, members []CompetitionMember) error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	competition.CreatedAt = now
+	competition.UpdatedAt = now
+	_, err = tx.NamedExec(insertCompetitionQuery, competition)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, problem := range problems {
+		problem.CreatedAt = now
+		problem.UpdatedAt = now
+		_, err = tx.NamedExec(insertProblemQuery, problem)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, attachment := range attachments {
+		attachment.CreatedAt = now
+		attachment.UpdatedAt = now
+		_, err = tx.NamedExec(insertAttachmentQuery, attachment)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, team := range teams {
+		team.CreatedAt = now
+		team.UpdatedAt = now
+		_, err = tx.NamedExec(insertTeamQuery, team)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, member := range members {
+		member.CreatedAt = now
+		member.UpdatedAt = now
+		_, err = tx.NamedExec(insertCompetitionMemberQuery, member)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// competitionContent deletes everything, which belongs to the competition. Rows, which only refer to the
+// competition's submissions or problems, go first.
+var competitionContent = []string{
+	"DELETE FROM judging_jobs WHERE submission_id IN (SELECT id FROM submissions WHERE competition_id=$1)",
+	"DELETE FROM idempotency_keys WHERE problem_id IN (SELECT id FROM problems WHERE competition_id=$1)",
+	"DELETE FROM rejudges WHERE competition_id=$1",
+	"DELETE FROM submissions WHERE competition_id=$1",
+	"DELETE FROM clarifications WHERE competition_id=$1",
+	"DELETE FROM announcements WHERE competition_id=$1",
+	"DELETE FROM attachments WHERE competition_id=$1",
+	"DELETE FROM competition_members WHERE competition_id=$1",
+	"DELETE FROM teams WHERE competition_id=$1",
+	"DELETE FROM problems WHERE competition_id=$1",
+	"DELETE FROM competitions WHERE id=$1",
+}
+
+// deleteCompetitionContent deletes the competition and everything, which belongs to it, inside the transaction. The
+// blob keys of the deleted attachments are returned, because blobs can only be deleted after the commit.
+func deleteCompetitionContent(tx *sqlx.Tx, id string) (blobKeys []string, err error) {
+	err = tx.Select(&blobKeys, "SELECT blob_key FROM attachments WHERE competition_id=$1", id)
+	if err != nil {
+		return nil, err
+	}
+	for _, query := range competitionContent {
+		_, err = tx.Exec(query, id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blobKeys, nil
+}
+
+// ImportCompetition inserts an imported competition with its problems, attachments, teams, submissions and the judging
+// jobs of its pending submissions in a single transaction. With replace the existing competition with the same ID and
+// everything, which belongs to it, is deleted first. The blob keys of the deleted attachments are returned, so that
+// the blobs can be deleted after the commit.
+func (db *sqlImpl) ImportCompetition(competition Competition, problems []Problem, attachments []Attachment, teams []Team, submissions []Submission, jobs []JudgingJob, replace bool) (blobKeys []string, err error) {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	if replace {
+		blobKeys, err = deleteCompetitionContent(tx, competition.ID)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	now := int(time.Now().Unix())
+	competition.CreatedAt = now
+	competition.UpdatedAt = now
+	_, err = tx.NamedExec(insertCompetitionQuery, competition)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	for _, problem := range problems {
+		problem.CreatedAt = now
+		problem.UpdatedAt = now
+		_, err = tx.NamedExec(insertProblemQuery, problem)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, attachment := range attachments {
+		attachment.CreatedAt = now
+		attachment.UpdatedAt = now
+		_, err = tx.NamedExec(insertAttachmentQuery, attachment)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, team := range teams {
+		team.CreatedAt = now
+		team.UpdatedAt = now
+		_, err = tx.NamedExec(insertTeamQuery, team)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, submission := range submissions {
+		submission.CreatedAt = now
+		submission.UpdatedAt = now
+		_, err = tx.NamedExec(insertSubmissionQuery, submission)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, job := range jobs {
+		job.CreatedAt = now
+		job.UpdatedAt = now
+		_, err = tx.NamedExec(insertJudgingJobQuery, job)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	return blobKeys, tx.Commit()
+}
+
 func (db *sqlImpl) GetCompetitions() (competitions []Competition, err error) {
 	err = db.db.Select(&competitions, "SELECT * FROM competitions ORDER BY status ASC")
 	return competitions, err
 }
 
+// GetActiveCompetitions returns the scheduled, running and paused competitions, which the scheduler has to watch.
+// Templates never change their status, so they are left out.
+func (db *sqlImpl) GetActiveCompetitions() (competitions []Competition, err error) {
+	err = db.db.Select(&competitions, "SELECT * FROM competitions WHERE (status=$1 OR status=$2 OR status=$3) AND is_template=false", StatusScheduled, StatusRunning, StatusPaused)
+	return competitions, err
+}
+
 func (db *sqlImpl) UpdateCompetition(competition Competition) error {
 	competition.UpdatedAt = int(time.Now().Unix())
 	_, err := db.db.NamedExec(
-		"UPDATE competitions SET name=:name, status=:status, start_time=:start_time, penalty=:penalty, penalty_each=:penalty_each, updated_at=:updated_at WHERE id=:id",
+		"UPDATE competitions SET name=:name, status=:status, start_time=:start_time, penalty=:penalty, penalty_each=:penalty_each, scoring_policy=:scoring_policy, scoring_parameters=:scoring_parameters, public_scoreboard=:public_scoreboard, freeze_time=:freeze_time, tie_breakers=:tie_breakers, planned_start=:planned_start, planned_end=:planned_end, duration=:duration, end_time=:end_time, paused_at=:paused_at, paused_time=:paused_time, is_template=:is_template, updated_at=:updated_at WHERE id=:id",
 		competition)
 	return err
 }
 
-func (db *sqlImpl) DeleteCompetition(id string) error {
-	_, err := db.db.Exec("DELETE FROM competitions WHERE id=$1", id)
-	return err
+// UpdateCompetitionStatus stores only the competition's status and the times set by the transition, and only if the
+// competition is still in the previous status, so that concurrent edits aren't overwritten. It returns false, if the
+// status was changed in the meantime. The planned end isn't stored, so resuming a paused competition has to go
+// through UpdateCompetition.
+func (db *sqlImpl) UpdateCompetitionStatus(competition Competition, previous int) (bool, error) {
+	result, err := db.db.Exec(
+		"UPDATE competitions SET status=$1, start_time=$2, end_time=$3, paused_at=$4, paused_time=$5, updated_at=$6 WHERE id=$7 AND status=$8",
+		competition.Status, competition.StartTime, competition.EndTime, competition.PausedAt, competition.PausedTime, int(time.Now().Unix()), competition.ID, previous)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// DeleteCompetition deletes the competition with everything, which belongs to it, in a single transaction. The blob
+// keys of the deleted attachments are returned, so that the blobs can be deleted after the commit.
+func (db *sqlImpl) DeleteCompetition(id string) (blobKeys []string, err error) {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	blobKeys, err = deleteCompetitionContent(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return blobKeys, tx.Commit()
 }
@@ -0,0 +1,51 @@
+package db
+
+import "time"
+
+// CompetitionMember gives the user a role (organiser, judge or viewer) in a single competition.
+type CompetitionMember struct {
+	ID            string
+	CompetitionID string `db:"competition_id"`
+	UserID        string `db:"user_id"`
+	Role          string
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+func (db *sqlImpl) GetCompetitionMember(competitionId string, userId string) (member CompetitionMember, err error) {
+	err = db.db.Get(&member, "SELECT * FROM competition_members WHERE competition_id=$1 AND user_id=$2", competitionId, userId)
+	return member, err
+}
+
+func (db *sqlImpl) GetCompetitionMembers(competitionId string) (members []CompetitionMember, err error) {
+	err = db.db.Select(&members, "SELECT * FROM competition_members WHERE competition_id=$1 ORDER BY created_at ASC", competitionId)
+	return members, err
+}
+
+func (db *sqlImpl) GetUserMemberships(userId string) (members []CompetitionMember, err error) {
+	err = db.db.Select(&members, "SELECT * FROM competition_members WHERE user_id=$1", userId)
+	return members, err
+}
+
+const insertCompetitionMemberQuery = `INSERT INTO competition_members (id, competition_id, user_id, role, created_at, updated_at) VALUES (:id, :competition_id, :user_id, :role, :created_at, :updated_at)`
+
+func (db *sqlImpl) InsertCompetitionMember(member CompetitionMember) (err error) {
+	member.CreatedAt = int(time.Now().Unix())
+	member.UpdatedAt = member.CreatedAt
+	_, err = db.db.NamedExec(insertCompetitionMemberQuery, member)
+	return err
+}
+
+func (db *sqlImpl) UpdateCompetitionMember(member CompetitionMember) error {
+	member.UpdatedAt = int(time.Now().Unix())
+	_, err := db.db.NamedExec(
+		"UPDATE competition_members SET role=:role, updated_at=:updated_at WHERE id=:id",
+		member)
+	return err
+}
+
+func (db *sqlImpl) DeleteCompetitionMember(id string) error {
+	_, err := db.db.Exec("DELETE FROM competition_members WHERE id=$1", id)
+	return err
+}
@@ -6,10 +6,14 @@ import (
 )
 
 type Config struct {
-	DatabaseName   string `json:"database_name"`
-	DatabaseConfig string `json:"database_config"`
-	Debug          bool   `json:"debug"`
-	Host           string `json:"host"`
+	DatabaseName      string `json:"database_name"`
+	DatabaseConfig    string `json:"database_config"`
+	Debug             bool   `json:"debug"`
+	Host              string `json:"host"`
+	JudgeWorkers      int    `json:"judge_workers"`      // number of concurrent judging workers
+	IdempotencyWindow int    `json:"idempotency_window"` // seconds, during which a repeated Idempotency-Key returns the original submission
+	ScoreboardCache   int    `json:"scoreboard_cache"`   // seconds, for which leaderboards are cached
+	BlobDirectory     string `json:"blob_directory"`     // directory, in which problem attachments are stored
 }
 
 func GetConfig() (Config, error) {
@@ -17,10 +21,14 @@ func GetConfig() (Config, error) {
 	file, err := os.ReadFile("config.json")
 	if err != nil {
 		marshal, err := json.Marshal(Config{
-			DatabaseName:   "sqlite3",
-			DatabaseConfig: "database/database.sqlite3",
-			Debug:          true,
-			Host:           "127.0.0.1:8000",
+			DatabaseName:      "sqlite3",
+			DatabaseConfig:    "database/database.sqlite3",
+			Debug:             true,
+			Host:              "127.0.0.1:8000",
+			JudgeWorkers:      4,
+			IdempotencyWindow: 24 * 60 * 60,
+			ScoreboardCache:   10,
+			BlobDirectory:     "blobs",
 		})
 		if err != nil {
 			return config, err
@@ -0,0 +1,42 @@
+package db
+
+import "time"
+
+// IdempotencyKey maps a client supplied key to the submission, which was created by the first request with that key.
+// Keys are scoped per user.
+type IdempotencyKey struct {
+	ID           string
+	Key          string `db:"idempotency_key"`
+	UserID       string `db:"user_id"`
+	ProblemID    string `db:"problem_id"`
+	TeamID       string `db:"team_id"`
+	SubmissionID string `db:"submission_id"`
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+func (db *sqlImpl) GetIdempotencyKey(userId string, key string) (idempotencyKey IdempotencyKey, err error) {
+	err = db.db.Get(&idempotencyKey, "SELECT * FROM idempotency_keys WHERE user_id=$1 AND idempotency_key=$2", userId, key)
+	return idempotencyKey, err
+}
+
+// InsertIdempotencyKey fails if the user has already used the key, so concurrent retries can't both create a submission.
+func (db *sqlImpl) InsertIdempotencyKey(idempotencyKey IdempotencyKey) (err error) {
+	idempotencyKey.CreatedAt = int(time.Now().Unix())
+	idempotencyKey.UpdatedAt = idempotencyKey.CreatedAt
+	_, err = db.db.NamedExec(
+		`INSERT INTO idempotency_keys (id, idempotency_key, user_id, problem_id, team_id, submission_id, created_at, updated_at) VALUES (:id, :idempotency_key, :user_id, :problem_id, :team_id, :submission_id, :created_at, :updated_at)`,
+		idempotencyKey)
+	return err
+}
+
+func (db *sqlImpl) DeleteIdempotencyKey(id string) error {
+	_, err := db.db.Exec("DELETE FROM idempotency_keys WHERE id=$1", id)
+	return err
+}
+
+func (db *sqlImpl) DeleteExpiredIdempotencyKeys(createdBefore int) error {
+	_, err := db.db.Exec("DELETE FROM idempotency_keys WHERE created_at < $1", createdBefore)
+	return err
+}
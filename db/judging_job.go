@@ -0,0 +1,61 @@
+package db
+
+import "time"
+
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// JudgingJob is a durable entry of the judging queue. Jobs, that were running when the server stopped, are queued again on startup.
+type JudgingJob struct {
+	ID           string
+	SubmissionID string `db:"submission_id"`
+	Status       string
+	Attempts     int
+	Error        string
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+func (db *sqlImpl) GetJudgingJob(id string) (job JudgingJob, err error) {
+	err = db.db.Get(&job, "SELECT * FROM judging_jobs WHERE id=$1", id)
+	return job, err
+}
+
+const insertJudgingJobQuery = `INSERT INTO judging_jobs (id, submission_id, status, attempts, error, created_at, updated_at) VALUES (:id, :submission_id, :status, :attempts, :error, :created_at, :updated_at)`
+
+func (db *sqlImpl) InsertJudgingJob(job JudgingJob) (err error) {
+	job.CreatedAt = int(time.Now().Unix())
+	job.UpdatedAt = job.CreatedAt
+	_, err = db.db.NamedExec(insertJudgingJobQuery, job)
+	return err
+}
+
+func (db *sqlImpl) GetQueuedJudgingJobs(limit int) (jobs []JudgingJob, err error) {
+	err = db.db.Select(&jobs, "SELECT * FROM judging_jobs WHERE status=$1 ORDER BY created_at ASC LIMIT $2", JobQueued, limit)
+	return jobs, err
+}
+
+func (db *sqlImpl) UpdateJudgingJob(job JudgingJob) error {
+	job.UpdatedAt = int(time.Now().Unix())
+	_, err := db.db.NamedExec(
+		"UPDATE judging_jobs SET submission_id=:submission_id, status=:status, attempts=:attempts, error=:error, updated_at=:updated_at WHERE id=:id",
+		job)
+	return err
+}
+
+// ResetRunningJudgingJobs queues all running jobs again. It should only be called on startup, before any worker is running.
+func (db *sqlImpl) ResetRunningJudgingJobs() error {
+	_, err := db.db.Exec("UPDATE judging_jobs SET status=$1, updated_at=$2 WHERE status=$3", JobQueued, int(time.Now().Unix()), JobRunning)
+	return err
+}
+
+// GetOrphanedSubmissions returns the pending submissions, which have neither a queued nor a running judging job.
+func (db *sqlImpl) GetOrphanedSubmissions() (submissions []Submission, err error) {
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE verdict='PENDING' AND superseded=false AND NOT EXISTS (SELECT 1 FROM judging_jobs WHERE judging_jobs.submission_id=submissions.id AND judging_jobs.status IN ($1, $2)) ORDER BY submitted_seconds ASC", JobQueued, JobRunning)
+	return submissions, err
+}
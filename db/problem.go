@@ -1,32 +1,54 @@
 package db
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Problem struct {
-	ID              string
-	Name            string
-	Solution        string
-	Position        int
-	Points          int
-	CompetitionID   string `db:"competition_id"`
-	AuthorID        string `db:"author_id"`
-	IsBaseLogicOnly bool   `db:"is_base_logic_only"`
+	ID                   string
+	Name                 string
+	Solution             string
+	AlternativeSolutions string `db:"alternative_solutions"` // semicolon separated reference solutions, equivalent to Solution
+	Position             int
+	Points               int
+	CompetitionID        string `db:"competition_id"`
+	AuthorID             string `db:"author_id"`
+	IsBaseLogicOnly      bool   `db:"is_base_logic_only"`
+	SampleSize           int    `db:"sample_size"`       // number of random input vectors to test, 0 means exhaustive testing
+	MandatoryVectors     string `db:"mandatory_vectors"` // comma separated input vectors, that are always tested when sampling
+	RowWeights           string `db:"row_weights"`       // semicolon separated row weights, see ast.ParseRowWeights
+	Statement            string // Markdown task text, shown to teams once the competition starts
+	InputDescription     string `db:"input_description"`  // Markdown description of the inputs
+	OutputDescription    string `db:"output_description"` // Markdown description of the expected outputs
 
 	CreatedAt int `db:"created_at"`
 	UpdatedAt int `db:"updated_at"`
 }
 
+// ReferenceSolutions returns all reference solutions of the problem, with the primary Solution being the first one.
+func (problem Problem) ReferenceSolutions() []string {
+	solutions := []string{problem.Solution}
+	for _, v := range strings.Split(problem.AlternativeSolutions, ";") {
+		if v == "" {
+			continue
+		}
+		solutions = append(solutions, v)
+	}
+	return solutions
+}
+
 func (db *sqlImpl) GetProblem(id string) (problem Problem, err error) {
 	err = db.db.Get(&problem, "SELECT * FROM problems WHERE id=$1", id)
 	return problem, err
 }
 
+const insertProblemQuery = `INSERT INTO problems (id, name, solution, alternative_solutions, position, points, competition_id, author_id, is_base_logic_only, sample_size, mandatory_vectors, row_weights, statement, input_description, output_description, created_at, updated_at) VALUES (:id, :name, :solution, :alternative_solutions, :position, :points, :competition_id, :author_id, :is_base_logic_only, :sample_size, :mandatory_vectors, :row_weights, :statement, :input_description, :output_description, :created_at, :updated_at)`
+
 func (db *sqlImpl) InsertProblem(problem Problem) (err error) {
 	problem.CreatedAt = int(time.Now().Unix())
 	problem.UpdatedAt = problem.CreatedAt
-	_, err = db.db.NamedExec(
-		`INSERT INTO problems (id, name, solution, position, points, competition_id, author_id, is_base_logic_only, created_at, updated_at) VALUES (:id, :name, :solution, :position, :points, :competition_id, :author_id, :is_base_logic_only, :created_at, :updated_at)`,
-		problem)
+	_, err = db.db.NamedExec(insertProblemQuery, problem)
 	return err
 }
 
@@ -37,7 +59,7 @@ func (db *sqlImpl) GetProblemsForCompetition(competitionId string) (problems []P
 
 func (db *sqlImpl) UpdateProblem(problem Problem) error {
 	_, err := db.db.NamedExec(
-		"UPDATE problems SET name=:name, solution=:solution, position=:position, points=:points, updated_at=:updated_at, competition_id=:competition_id, author_id=:author_id, is_base_logic_only=:is_base_logic_only WHERE id=:id",
+		"UPDATE problems SET name=:name, solution=:solution, alternative_solutions=:alternative_solutions, position=:position, points=:points, updated_at=:updated_at, competition_id=:competition_id, author_id=:author_id, is_base_logic_only=:is_base_logic_only, sample_size=:sample_size, mandatory_vectors=:mandatory_vectors, row_weights=:row_weights, statement=:statement, input_description=:input_description, output_description=:output_description WHERE id=:id",
 		problem)
 	return err
 }
@@ -0,0 +1,63 @@
+package db
+
+import "time"
+
+// Rejudge records the result of rejudging a single submission. Results of the same rejudge run share the RejudgeID.
+type Rejudge struct {
+	ID            string
+	RejudgeID     string `db:"rejudge_id"`
+	SubmissionID  string `db:"submission_id"`
+	CompetitionID string `db:"competition_id"`
+	ProblemID     string `db:"problem_id"`
+	UserID        string `db:"user_id"`
+	OldVerdict    string `db:"old_verdict"`
+	NewVerdict    string `db:"new_verdict"`
+	OldScore      int    `db:"old_score"`
+	NewScore      int    `db:"new_score"`
+
+	CreatedAt int `db:"created_at"`
+	UpdatedAt int `db:"updated_at"`
+}
+
+const insertRejudgeQuery = `INSERT INTO rejudges (id, rejudge_id, submission_id, competition_id, problem_id, user_id, old_verdict, new_verdict, old_score, new_score, created_at, updated_at) VALUES
+(:id, :rejudge_id, :submission_id, :competition_id, :problem_id, :user_id, :old_verdict, :new_verdict, :old_score, :new_score, :created_at, :updated_at)`
+
+func (db *sqlImpl) InsertRejudge(rejudge Rejudge) (err error) {
+	rejudge.CreatedAt = int(time.Now().Unix())
+	rejudge.UpdatedAt = rejudge.CreatedAt
+	_, err = db.db.NamedExec(insertRejudgeQuery, rejudge)
+	return err
+}
+
+func (db *sqlImpl) GetRejudges(rejudgeId string) (rejudges []Rejudge, err error) {
+	err = db.db.Select(&rejudges, "SELECT * FROM rejudges WHERE rejudge_id=$1 ORDER BY created_at ASC", rejudgeId)
+	return rejudges, err
+}
+
+// SaveRejudge updates the rejudged submissions and records their results in a single transaction, so that a failed
+// rejudge run leaves no submission half rejudged.
+func (db *sqlImpl) SaveRejudge(submissions []Submission, rejudges []Rejudge) error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	for _, submission := range submissions {
+		submission.UpdatedAt = now
+		_, err = tx.NamedExec(updateSubmissionQuery, submission)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, rejudge := range rejudges {
+		rejudge.CreatedAt = now
+		rejudge.UpdatedAt = now
+		_, err = tx.NamedExec(insertRejudgeQuery, rejudge)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -62,4 +62,110 @@ CREATE TABLE IF NOT EXISTS teams (
 	created_at               INTEGER,
 	updated_at               INTEGER
 );
+
+CREATE TABLE IF NOT EXISTS rejudges (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	rejudge_id               VARCHAR(40)    NOT NULL,
+	submission_id            VARCHAR(40)    NOT NULL,
+	competition_id           VARCHAR(40)    NOT NULL,
+	problem_id               VARCHAR(40)    NOT NULL,
+	user_id                  VARCHAR(40)    NOT NULL,
+	old_verdict              VARCHAR(40)    NOT NULL,
+	new_verdict              VARCHAR(40)    NOT NULL,
+	old_score                INTEGER        NOT NULL,
+	new_score                INTEGER        NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS judging_jobs (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	submission_id            VARCHAR(40)    NOT NULL,
+	status                   VARCHAR(40)    NOT NULL,
+	attempts                 INTEGER        NOT NULL,
+	error                    VARCHAR(2000)  NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	idempotency_key          VARCHAR(255)   NOT NULL,
+	user_id                  VARCHAR(40)    NOT NULL,
+	problem_id               VARCHAR(40)    NOT NULL,
+	team_id                  VARCHAR(40)    NOT NULL,
+	submission_id            VARCHAR(40)    NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER,
+	UNIQUE (user_id, idempotency_key)
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	user_id                  VARCHAR(40)    NOT NULL,
+	username                 VARCHAR(250)   NOT NULL,
+	action                   VARCHAR(40)    NOT NULL,
+	entity_type              VARCHAR(40)    NOT NULL,
+	entity_id                VARCHAR(40)    NOT NULL,
+	competition_id           VARCHAR(40)    NOT NULL,
+	snapshot_before          TEXT           NOT NULL,
+	snapshot_after           TEXT           NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS clarifications (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	competition_id           VARCHAR(40)    NOT NULL,
+	problem_id               VARCHAR(40)    NOT NULL,
+	team_id                  VARCHAR(40)    NOT NULL,
+	question                 VARCHAR(5000)  NOT NULL,
+	answer                   VARCHAR(5000)  NOT NULL,
+	asked_by                 VARCHAR(40)    NOT NULL,
+	answered_by              VARCHAR(40)    NOT NULL,
+	public                   BOOLEAN        NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS announcements (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	competition_id           VARCHAR(40)    NOT NULL,
+	problem_id               VARCHAR(40)    NOT NULL,
+	text                     VARCHAR(5000)  NOT NULL,
+	author_id                VARCHAR(40)    NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS competition_members (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	competition_id           VARCHAR(40)    NOT NULL,
+	user_id                  VARCHAR(40)    NOT NULL,
+	role                     VARCHAR(40)    NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER,
+	UNIQUE (competition_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS attachments (
+	id                       VARCHAR(40)    PRIMARY KEY,
+	problem_id               VARCHAR(40)    NOT NULL,
+	competition_id           VARCHAR(40)    NOT NULL,
+	name                     VARCHAR(250)   NOT NULL,
+	content_type             VARCHAR(250)   NOT NULL,
+	size                     INTEGER        NOT NULL,
+	blob_key                 VARCHAR(40)    NOT NULL,
+	uploaded_by              VARCHAR(40)    NOT NULL,
+	
+	created_at               INTEGER,
+	updated_at               INTEGER
+);
 `
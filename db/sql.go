@@ -27,6 +27,7 @@ type SQL interface {
 	GetUserByLoginToken(loginToken string) (user User, err error)
 	InsertUser(user User) (err error)
 	GetUserByUsername(username string) (user User, err error)
+	GetTeamUser(teamId string) (user User, err error)
 	CheckIfAdminIsCreated() bool
 	GetUsers() (users []User, err error)
 	UpdateUser(user User) error
@@ -34,9 +35,13 @@ type SQL interface {
 
 	GetCompetition(id string) (competition Competition, err error)
 	InsertCompetition(competition Competition) (err error)
+	InsertCompetitionCopy(competition Competition, problems []Problem, attachments []Attachment, teams []Team, members []CompetitionMember) error
+	ImportCompetition(competition Competition, problems []Problem, attachments []Attachment, teams []Team, submissions []Submission, jobs []JudgingJob, replace bool) (blobKeys []string, err error)
 	GetCompetitions() (competitions []Competition, err error)
+	GetActiveCompetitions() (competitions []Competition, err error)
 	UpdateCompetition(competition Competition) error
-	DeleteCompetition(id string) error
+	UpdateCompetitionStatus(competition Competition, previous int) (bool, error)
+	DeleteCompetition(id string) (blobKeys []string, err error)
 
 	GetProblem(id string) (problem Problem, err error)
 	InsertProblem(problem Problem) (err error)
@@ -46,16 +51,70 @@ type SQL interface {
 
 	GetSubmission(id string) (submission Submission, err error)
 	InsertSubmission(submission Submission) (err error)
-	GetPastSubmissionsForProblem(submittedAfter int, teamId string, problemId string) (submissions []Submission, err error)
+	InsertQueuedSubmission(submission Submission, job JudgingJob) error
+	InsertSubmissionRevision(submission Submission, previous Submission, job JudgingJob) error
+	GetPastSubmissionsForProblem(submittedSeconds int, teamId string, problemId string) (submissions []Submission, err error)
 	GetTeamSubmissionsForProblem(teamId string, problemId string) (submissions []Submission, err error)
+	GetTeamSubmissions(teamId string) (submissions []Submission, err error)
+	GetSubmissionsForProblem(problemId string) (submissions []Submission, err error)
+	GetSubmissionsForCompetition(competitionId string) (submissions []Submission, err error)
+	GetPublicSubmissionsForCompetition(competitionId string) (submissions []Submission, err error)
+	GetSubmissionHistoryForCompetition(competitionId string) (submissions []Submission, err error)
+	GetSubmissionRevision(id string) (submission Submission, err error)
 	UpdateSubmission(submission Submission) error
+	UpdateSubmissionResult(submission Submission) (bool, error)
 	DeleteSubmission(id string) error
 
 	GetTeam(id string) (team Team, err error)
 	InsertTeam(team Team) (err error)
+	InsertTeams(teams []Team) error
 	GetTeamsForCompetition(competitionId string) (teams []Team, err error)
 	UpdateTeam(team Team) error
 	DeleteTeam(id string) error
+
+	InsertRejudge(rejudge Rejudge) (err error)
+	GetRejudges(rejudgeId string) (rejudges []Rejudge, err error)
+	SaveRejudge(submissions []Submission, rejudges []Rejudge) error
+
+	GetJudgingJob(id string) (job JudgingJob, err error)
+	InsertJudgingJob(job JudgingJob) (err error)
+	GetQueuedJudgingJobs(limit int) (jobs []JudgingJob, err error)
+	UpdateJudgingJob(job JudgingJob) error
+	ResetRunningJudgingJobs() error
+	GetOrphanedSubmissions() (submissions []Submission, err error)
+
+	GetIdempotencyKey(userId string, key string) (idempotencyKey IdempotencyKey, err error)
+	InsertIdempotencyKey(idempotencyKey IdempotencyKey) (err error)
+	DeleteIdempotencyKey(id string) error
+	DeleteExpiredIdempotencyKeys(createdBefore int) error
+
+	InsertAuditEntry(entry AuditEntry) (err error)
+	GetAuditLog(filter AuditFilter) (entries []AuditEntry, total int, err error)
+
+	GetClarification(id string) (clarification Clarification, err error)
+	InsertClarification(clarification Clarification) (err error)
+	GetClarificationsForCompetition(competitionId string) (clarifications []Clarification, err error)
+	GetTeamClarifications(competitionId string, teamId string) (clarifications []Clarification, err error)
+	UpdateClarification(clarification Clarification) error
+	DeleteClarification(id string) error
+
+	GetAnnouncement(id string) (announcement Announcement, err error)
+	InsertAnnouncement(announcement Announcement) (err error)
+	GetAnnouncementsForCompetition(competitionId string) (announcements []Announcement, err error)
+	DeleteAnnouncement(id string) error
+
+	GetCompetitionMember(competitionId string, userId string) (member CompetitionMember, err error)
+	GetCompetitionMembers(competitionId string) (members []CompetitionMember, err error)
+	GetUserMemberships(userId string) (members []CompetitionMember, err error)
+	InsertCompetitionMember(member CompetitionMember) (err error)
+	UpdateCompetitionMember(member CompetitionMember) error
+	DeleteCompetitionMember(id string) error
+
+	GetAttachment(id string) (attachment Attachment, err error)
+	GetAttachmentsForProblem(problemId string) (attachments []Attachment, err error)
+	GetAttachmentsForCompetition(competitionId string) (attachments []Attachment, err error)
+	InsertAttachment(attachment Attachment) (err error)
+	DeleteAttachment(id string) error
 }
 
 func NewSQL(driver string, drivername string, logger *zap.SugaredLogger) (SQL, error) {
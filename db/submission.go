@@ -1,20 +1,35 @@
 package db
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrAlreadySuperseded is returned, when a revision of a submission, which already has a newer revision, is inserted.
+var ErrAlreadySuperseded = errors.New("submission was already superseded by a newer revision")
+
 type Submission struct {
-	ID             string
-	Solution       string // submitted solution
-	Verdict        string
-	Score          int
-	SubmittedAfter int    `db:"submitted_after"` // after how many minutes has it been submitted
-	SubmissionLog  string `db:"submission_log"`
-	CompetitionID  string `db:"competition_id"`
-	ProblemID      string `db:"problem_id"`
-	TeamID         string `db:"team_id"`
-	Public         bool   // whether the submission is displayed on leaderboards
+	ID                string
+	Solution          string // submitted solution
+	Verdict           string
+	Score             int
+	SubmittedAfter    int    `db:"submitted_after"`   // after how many minutes has it been submitted
+	SubmittedSeconds  int    `db:"submitted_seconds"` // contest time of the submission in seconds, SubmittedAfter is derived from it
+	TimeOverride      string `db:"time_override"`     // reason, why the submission time was entered manually. Empty if it was computed by the server
+	SubmissionLog     string `db:"submission_log"`
+	CompetitionID     string `db:"competition_id"`
+	ProblemID         string `db:"problem_id"`
+	TeamID            string `db:"team_id"`
+	Public            bool   // whether the submission is displayed on leaderboards
+	Seed              int64  // seed used for sampled testing, so that the result can be reproduced
+	SampleSize        int    `db:"sample_size"`        // number of sampled input vectors, 0 if the whole truth table was tested
+	PenaltyTime       int    `db:"penalty_time"`       // penalty minutes, given by the scoring policy
+	ScoringPolicy     string `db:"scoring_policy"`     // scoring policy used to score the submission
+	ScoringParameters string `db:"scoring_parameters"` // JSON encoded parameters of the scoring policy
+	Supersedes        string // ID of the previous revision, which this submission corrects
+	Superseded        bool   // whether a newer revision exists. Superseded revisions are kept only for history
+	CreatedBy         string `db:"created_by"` // ID of the user who created this revision
+	Revealed          bool   // whether the result was revealed after the scoreboard freeze
 
 	CreatedAt int `db:"created_at"`
 	UpdatedAt int `db:"updated_at"`
@@ -25,35 +40,173 @@ func (db *sqlImpl) GetSubmission(id string) (submission Submission, err error) {
 	return submission, err
 }
 
+const insertSubmissionQuery = `INSERT INTO submissions (id, solution, verdict, score, submitted_after, submitted_seconds, time_override, submission_log, competition_id, problem_id, team_id, public, seed, sample_size, penalty_time, scoring_policy, scoring_parameters, supersedes, superseded, created_by, revealed, created_at, updated_at) VALUES
+(:id, :solution, :verdict, :score, :submitted_after, :submitted_seconds, :time_override, :submission_log, :competition_id, :problem_id, :team_id, :public, :seed, :sample_size, :penalty_time, :scoring_policy, :scoring_parameters, :supersedes, :superseded, :created_by, :revealed, :created_at, :updated_at)`
+
 func (db *sqlImpl) InsertSubmission(submission Submission) (err error) {
 	submission.CreatedAt = int(time.Now().Unix())
 	submission.UpdatedAt = submission.CreatedAt
-	_, err = db.db.NamedExec(
-		`INSERT INTO submissions (id, solution, verdict, score, submitted_after, submission_log, competition_id, problem_id, team_id, public, created_at, updated_at) VALUES
-(:id, :solution, :verdict, :score, :submitted_after, :submission_log, :competition_id, :problem_id, :team_id, :public, :created_at, :updated_at)`,
-		submission)
+	_, err = db.db.NamedExec(insertSubmissionQuery, submission)
 	return err
 }
 
-func (db *sqlImpl) GetPastSubmissionsForProblem(submittedAfter int, teamId string, problemId string) (submissions []Submission, err error) {
-	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE submitted_after < $1 AND team_id=$2 AND problem_id=$3 AND public=true ORDER BY submitted_after ASC", submittedAfter, teamId, problemId)
+// InsertQueuedSubmission inserts the submission along with its judging job in a single transaction, so that a pending
+// submission can't be left without a job.
+func (db *sqlImpl) InsertQueuedSubmission(submission Submission, job JudgingJob) error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	submission.CreatedAt = now
+	submission.UpdatedAt = now
+	_, err = tx.NamedExec(insertSubmissionQuery, submission)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	_, err = tx.NamedExec(insertJudgingJobQuery, job)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// InsertSubmissionRevision inserts the new revision along with its judging job and marks the previous one as
+// superseded in a single transaction, so that the previous revision can't stay current next to the new one. If the
+// previous revision was superseded in the meantime, nothing is inserted and ErrAlreadySuperseded is returned.
+func (db *sqlImpl) InsertSubmissionRevision(submission Submission, previous Submission, job JudgingJob) error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	submission.CreatedAt = now
+	submission.UpdatedAt = now
+	_, err = tx.NamedExec(insertSubmissionQuery, submission)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	_, err = tx.NamedExec(insertJudgingJobQuery, job)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	// zapiše se le zastavica, da se ne povozi medtem shranjena ocena, pogoj pa prepreči razvejitev verige
+	result, err := tx.Exec("UPDATE submissions SET superseded=true, updated_at=$1 WHERE id=$2 AND superseded=false", now, previous.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if affected != 1 {
+		tx.Rollback()
+		return ErrAlreadySuperseded
+	}
+	return tx.Commit()
+}
+
+func (db *sqlImpl) GetPastSubmissionsForProblem(submittedSeconds int, teamId string, problemId string) (submissions []Submission, err error) {
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE submitted_seconds < $1 AND team_id=$2 AND problem_id=$3 AND public=true AND superseded=false ORDER BY submitted_seconds ASC", submittedSeconds, teamId, problemId)
 	return submissions, err
 }
 
 func (db *sqlImpl) GetTeamSubmissionsForProblem(teamId string, problemId string) (submissions []Submission, err error) {
-	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE team_id=$1 AND problem_id=$2 AND public=true ORDER BY submitted_after ASC", teamId, problemId)
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE team_id=$1 AND problem_id=$2 AND public=true AND superseded=false ORDER BY submitted_seconds ASC", teamId, problemId)
+	return submissions, err
+}
+
+// GetTeamSubmissions returns all current (not superseded) submissions of the team, public or not.
+func (db *sqlImpl) GetTeamSubmissions(teamId string) (submissions []Submission, err error) {
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE team_id=$1 AND superseded=false ORDER BY submitted_seconds ASC", teamId)
+	return submissions, err
+}
+
+func (db *sqlImpl) GetSubmissionsForProblem(problemId string) (submissions []Submission, err error) {
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE problem_id=$1 AND superseded=false ORDER BY submitted_seconds ASC", problemId)
+	return submissions, err
+}
+
+func (db *sqlImpl) GetSubmissionsForCompetition(competitionId string) (submissions []Submission, err error) {
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE competition_id=$1 AND superseded=false ORDER BY submitted_seconds ASC", competitionId)
 	return submissions, err
 }
 
+// GetPublicSubmissionsForCompetition returns every submission shown on the leaderboard, so that it can be built with a single query.
+func (db *sqlImpl) GetPublicSubmissionsForCompetition(competitionId string) (submissions []Submission, err error) {
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE competition_id=$1 AND public=true AND superseded=false ORDER BY submitted_seconds ASC", competitionId)
+	return submissions, err
+}
+
+// GetSubmissionHistoryForCompetition returns all submissions of the competition, superseded revisions included.
+func (db *sqlImpl) GetSubmissionHistoryForCompetition(competitionId string) (submissions []Submission, err error) {
+	err = db.db.Select(&submissions, "SELECT * FROM submissions WHERE competition_id=$1 ORDER BY submitted_seconds ASC, created_at ASC", competitionId)
+	return submissions, err
+}
+
+// GetSubmissionRevision returns the revision, which directly supersedes the submission.
+func (db *sqlImpl) GetSubmissionRevision(id string) (submission Submission, err error) {
+	err = db.db.Get(&submission, "SELECT * FROM submissions WHERE supersedes=$1", id)
+	return submission, err
+}
+
+const updateSubmissionQuery = "UPDATE submissions SET solution=:solution, verdict=:verdict, score=:score, submitted_after=:submitted_after, submitted_seconds=:submitted_seconds, time_override=:time_override, submission_log=:submission_log, competition_id=:competition_id, problem_id=:problem_id, team_id=:team_id, public=:public, seed=:seed, sample_size=:sample_size, penalty_time=:penalty_time, scoring_policy=:scoring_policy, scoring_parameters=:scoring_parameters, supersedes=:supersedes, superseded=:superseded, created_by=:created_by, revealed=:revealed, updated_at=:updated_at WHERE id=:id"
+
 func (db *sqlImpl) UpdateSubmission(submission Submission) error {
 	submission.UpdatedAt = int(time.Now().Unix())
-	_, err := db.db.NamedExec(
-		"UPDATE submissions SET solution=:solution, verdict=:verdict, score=:score, submitted_after=:submitted_after, submission_log=:submission_log, competition_id=:competition_id, problem_id=:problem_id, team_id=:team_id, public=:public, updated_at=:updated_at WHERE id=:id",
-		submission)
+	_, err := db.db.NamedExec(updateSubmissionQuery, submission)
 	return err
 }
 
+// UpdateSubmissionResult stores the judging result of a pending submission. Only the result columns are written, and
+// only while the submission is still pending, so that a manual verdict set in the meantime isn't overwritten. It
+// returns false, if the submission wasn't pending anymore.
+func (db *sqlImpl) UpdateSubmissionResult(submission Submission) (bool, error) {
+	submission.UpdatedAt = int(time.Now().Unix())
+	result, err := db.db.NamedExec(
+		"UPDATE submissions SET verdict=:verdict, score=:score, submission_log=:submission_log, seed=:seed, sample_size=:sample_size, penalty_time=:penalty_time, scoring_policy=:scoring_policy, scoring_parameters=:scoring_parameters, updated_at=:updated_at WHERE id=:id AND verdict='PENDING'",
+		submission)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// DeleteSubmission deletes the submission. If it is the latest revision of a corrected submission, the previous
+// revision becomes current again, in the same transaction.
 func (db *sqlImpl) DeleteSubmission(id string) error {
-	_, err := db.db.Exec("DELETE FROM submissions WHERE id=$1", id)
-	return err
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+	var submission Submission
+	err = tx.Get(&submission, "SELECT * FROM submissions WHERE id=$1", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM submissions WHERE id=$1", id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if submission.Supersedes != "" && !submission.Superseded {
+		_, err = tx.Exec("UPDATE submissions SET superseded=false, updated_at=$1 WHERE id=$2", int(time.Now().Unix()), submission.Supersedes)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
 }
@@ -16,15 +16,34 @@ func (db *sqlImpl) GetTeam(id string) (team Team, err error) {
 	return team, err
 }
 
+const insertTeamQuery = `INSERT INTO teams (id, name, competition_id, created_at, updated_at) VALUES (:id, :name, :competition_id, :created_at, :updated_at)`
+
 func (db *sqlImpl) InsertTeam(team Team) (err error) {
 	team.CreatedAt = int(time.Now().Unix())
 	team.UpdatedAt = team.CreatedAt
-	_, err = db.db.NamedExec(
-		`INSERT INTO teams (id, name, competition_id, created_at, updated_at) VALUES (:id, :name, :competition_id, :created_at, :updated_at)`,
-		team)
+	_, err = db.db.NamedExec(insertTeamQuery, team)
 	return err
 }
 
+// InsertTeams inserts all teams in a single transaction, so either all or none of them are created.
+func (db *sqlImpl) InsertTeams(teams []Team) error {
+	tx, err := db.db.Beginx()
+	if err != nil {
+		return err
+	}
+	now := int(time.Now().Unix())
+	for _, team := range teams {
+		team.CreatedAt = now
+		team.UpdatedAt = now
+		_, err = tx.NamedExec(insertTeamQuery, team)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (db *sqlImpl) GetTeamsForCompetition(competitionId string) (teams []Team, err error) {
 	err = db.db.Select(&teams, "SELECT * FROM teams WHERE competition_id=$1 ORDER BY name ASC", competitionId)
 	return teams, err
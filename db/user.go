@@ -7,6 +7,7 @@ type User struct {
 	IsAdmin    bool   `db:"is_admin"`
 	LoginToken string `db:"login_token"`
 	IsLocked   bool   `db:"is_locked"`
+	TeamID     string `db:"team_id"` // team, the account logs in as. Empty for judges
 
 	CreatedAt int `db:"created_at"`
 	UpdatedAt int `db:"updated_at"`
@@ -27,10 +28,15 @@ func (db *sqlImpl) GetUserByUsername(email string) (user User, err error) {
 	return user, err
 }
 
+func (db *sqlImpl) GetTeamUser(teamId string) (user User, err error) {
+	err = db.db.Get(&user, "SELECT * FROM users WHERE team_id=$1", teamId)
+	return user, err
+}
+
 func (db *sqlImpl) InsertUser(user User) (err error) {
 	_, err = db.db.NamedExec(
-		`INSERT INTO users (id, username, pass, is_admin, login_token, is_locked, created_at, updated_at) 
-VALUES (:id, :username, :pass, :is_admin, :login_token, :is_locked, :created_at, :updated_at)`,
+		`INSERT INTO users (id, username, pass, is_admin, login_token, is_locked, team_id, created_at, updated_at) 
+VALUES (:id, :username, :pass, :is_admin, :login_token, :is_locked, :team_id, :created_at, :updated_at)`,
 		user)
 	return err
 }
@@ -52,7 +58,7 @@ func (db *sqlImpl) GetUsers() (users []User, err error) {
 
 func (db *sqlImpl) UpdateUser(user User) error {
 	_, err := db.db.NamedExec(
-		"UPDATE users SET pass=:pass, is_admin=:is_admin, login_token=:login_token, is_locked=:is_locked, created_at=:created_at, updated_at=:updated_at WHERE id=:id",
+		"UPDATE users SET username=:username, pass=:pass, is_admin=:is_admin, login_token=:login_token, is_locked=:is_locked, team_id=:team_id, created_at=:created_at, updated_at=:updated_at WHERE id=:id",
 		user)
 	return err
 }
@@ -0,0 +1,238 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxAttachmentSize limits uploaded attachments to 20 MiB.
+const maxAttachmentSize = 20 << 20
+
+// inlineContentTypes are shown in the browser, everything else is downloaded.
+var inlineContentTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf"}
+
+// statementVisible reports whether teams may already see the competition's problem statements and attachments.
+// The status decides, since imported and cloned drafts may keep an old start time.
+func statementVisible(competition db.Competition) bool {
+	return slices.Contains([]int{db.StatusRunning, db.StatusPaused, db.StatusFinished, db.StatusArchived}, competition.Status)
+}
+
+// problemAttachmentAccess authorizes access to the problem's attachments. Teams see them only once the competition
+// has started.
+func (server *httpImpl) problemAttachmentAccess(w http.ResponseWriter, user db.User, competitionId string) bool {
+	team, ok := server.authorizeTeam(w, user, competitionId, PermViewProblems)
+	if !ok {
+		return false
+	}
+	if team == nil {
+		return true
+	}
+	competition, err := server.db.GetCompetition(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return false
+	}
+	if !statementVisible(competition) {
+		WriteJSON(w, Response{Error: "Competition hasn't started yet"}, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (server *httpImpl) GetAttachments(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	problem, err := server.db.GetProblem(mux.Vars(r)["problem_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching a problem"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.problemAttachmentAccess(w, user, problem.CompetitionID) {
+		return
+	}
+
+	attachments, err := server.db.GetAttachmentsForProblem(problem.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching attachments"}, http.StatusInternalServerError)
+		return
+	}
+
+	if attachments == nil {
+		attachments = make([]db.Attachment, 0)
+	}
+
+	WriteJSON(w, Response{Data: attachments}, http.StatusOK)
+}
+
+// UploadAttachment stores the uploaded file (file) in the blob store and attaches it to the problem.
+func (server *httpImpl) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	problem, err := server.db.GetProblem(mux.Vars(r)["problem_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching a problem"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, problem.CompetitionID, PermManage) {
+		return
+	}
+
+	// nekaj prostora pustimo za ostale dele obrazca
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		WriteJSON(w, Response{Error: "File is missing or too large"}, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxAttachmentSize {
+		WriteJSON(w, Response{Error: "File is too large"}, http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		WriteJSON(w, Response{Error: "File name is invalid"}, http.StatusBadRequest)
+		return
+	}
+	if runes := []rune(name); len(runes) > 250 {
+		name = string(runes[len(runes)-250:])
+	}
+
+	// vrsto določimo po končnici, sicer po vsebini
+	contentType := mime.TypeByExtension(strings.ToLower(filepath.Ext(name)))
+	if contentType == "" {
+		sniff := make([]byte, 512)
+		n, _ := io.ReadFull(file, sniff)
+		contentType = http.DetectContentType(sniff[:n])
+		_, err = file.Seek(0, io.SeekStart)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst reading the file"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	attachment := db.Attachment{
+		ID:            uuid.NewString(),
+		ProblemID:     problem.ID,
+		CompetitionID: problem.CompetitionID,
+		Name:          name,
+		ContentType:   contentType,
+		BlobKey:       uuid.NewString(),
+		UploadedBy:    user.ID,
+	}
+
+	size, err := server.blobs.Put(attachment.BlobKey, file)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst storing the file"}, http.StatusInternalServerError)
+		return
+	}
+	attachment.Size = int(size)
+
+	err = server.db.InsertAttachment(attachment)
+	if err != nil {
+		server.blobs.Delete(attachment.BlobKey)
+		WriteJSON(w, Response{Error: "Server error whilst inserting attachment"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "create", "attachment", attachment.ID, attachment.CompetitionID, nil, attachment)
+
+	WriteJSON(w, Response{Data: attachment}, http.StatusCreated)
+}
+
+// DownloadAttachment serves the attachment's content. Only images and PDFs are shown inline.
+func (server *httpImpl) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, err := server.db.GetAttachment(mux.Vars(r)["attachment_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching attachment"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.problemAttachmentAccess(w, user, attachment.CompetitionID) {
+		return
+	}
+
+	blob, err := server.blobs.Get(attachment.BlobKey)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst reading the file"}, http.StatusInternalServerError)
+		return
+	}
+	defer blob.Close()
+
+	disposition := "attachment"
+	if slices.Contains(inlineContentTypes, attachment.ContentType) {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	w.Header().Set("Content-Length", strconv.Itoa(attachment.Size))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, blob)
+}
+
+func (server *httpImpl) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, err := server.db.GetAttachment(mux.Vars(r)["attachment_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching attachment"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, attachment.CompetitionID, PermManage) {
+		return
+	}
+
+	err = server.deleteAttachment(attachment)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst deleting attachment"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "delete", "attachment", attachment.ID, attachment.CompetitionID, attachment, nil)
+
+	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+}
+
+// deleteAttachment deletes the attachment and its blob. A blob, which can't be deleted, is only logged, since
+// nothing refers to it anymore.
+func (server *httpImpl) deleteAttachment(attachment db.Attachment) error {
+	err := server.db.DeleteAttachment(attachment.ID)
+	if err != nil {
+		return err
+	}
+	err = server.blobs.Delete(attachment.BlobKey)
+	if err != nil {
+		server.logger.Errorw("failed to delete a blob", "blob", attachment.BlobKey, "error", err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+// audit records an administrative action. before and after are snapshots of the entity, nil if the entity didn't
+// exist. Failing to write the entry is only logged, as the action itself has already been done.
+func (server *httpImpl) audit(user db.User, action string, entityType string, entityId string, competitionId string, before any, after any) {
+	entry := db.AuditEntry{
+		ID:            uuid.NewString(),
+		UserID:        user.ID,
+		Username:      user.Username,
+		Action:        action,
+		EntityType:    entityType,
+		EntityID:      entityId,
+		CompetitionID: competitionId,
+		Before:        auditSnapshot(before),
+		After:         auditSnapshot(after),
+	}
+	err := server.db.InsertAuditEntry(entry)
+	if err != nil {
+		server.logger.Errorw("failed to write an audit log entry", "action", action, "entity_type", entityType, "entity_id", entityId, "error", err.Error())
+	}
+}
+
+func auditSnapshot(v any) string {
+	if v == nil {
+		return ""
+	}
+	marshal, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(marshal)
+}
+
+// auditUser strips the credentials from the user's snapshot.
+func auditUser(user db.User) db.User {
+	user.Password = ""
+	user.LoginToken = ""
+	return user
+}
+
+type AuditLogPage struct {
+	Entries []db.AuditEntry
+	Total   int
+	Page    int
+	PerPage int
+}
+
+func (server *httpImpl) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := db.AuditFilter{
+		UserID:        query.Get("user_id"),
+		Action:        query.Get("action"),
+		EntityType:    query.Get("entity_type"),
+		EntityID:      query.Get("entity_id"),
+		CompetitionID: query.Get("competition_id"),
+	}
+
+	// organizatorji lahko pregledujejo dnevnik svojih tekmovanj, celoten dnevnik pa samo superadmini
+	if filter.CompetitionID == "" {
+		if !authorizeSuperadmin(w, user) {
+			return
+		}
+	} else if !server.authorize(w, user, filter.CompetitionID, PermManage) {
+		return
+	}
+
+	var err error
+
+	if query.Get("since") != "" {
+		filter.Since, err = strconv.Atoi(query.Get("since"))
+		if err != nil {
+			WriteJSON(w, Response{Error: "Since is invalid. Expected unix time."}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if query.Get("until") != "" {
+		filter.Until, err = strconv.Atoi(query.Get("until"))
+		if err != nil {
+			WriteJSON(w, Response{Error: "Until is invalid. Expected unix time."}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	page := 1
+	if query.Get("page") != "" {
+		page, err = strconv.Atoi(query.Get("page"))
+		if err != nil || page < 1 {
+			WriteJSON(w, Response{Error: "Page is invalid. Expected a positive number."}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	perPage := defaultAuditPageSize
+	if query.Get("per_page") != "" {
+		perPage, err = strconv.Atoi(query.Get("per_page"))
+		if err != nil || perPage < 1 || perPage > maxAuditPageSize {
+			WriteJSON(w, Response{Error: "Per_page is invalid. Expected integer on interval [1, 500]."}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	filter.Limit = perPage
+	filter.Offset = (page - 1) * perPage
+
+	entries, total, err := server.db.GetAuditLog(filter)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching the audit log"}, http.StatusInternalServerError)
+		return
+	}
+
+	if entries == nil {
+		entries = make([]db.AuditEntry, 0)
+	}
+
+	WriteJSON(w, Response{Data: AuditLogPage{Entries: entries, Total: total, Page: page, PerPage: perPage}}, http.StatusOK)
+}
@@ -0,0 +1,130 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// Roles of a user in a competition. Superadmins (User.IsAdmin) have every permission in every competition, teams
+// are team accounts (User.TeamID) in their own competition. Other roles are assigned through competition_members.
+const (
+	RoleNone       = ""
+	RoleTeam       = "team"
+	RoleViewer     = "viewer"
+	RoleJudge      = "judge"
+	RoleOrganiser  = "organiser"
+	RoleSuperadmin = "superadmin"
+)
+
+type Permission int
+
+const (
+	PermView         Permission = iota // leaderboard and teams
+	PermViewProblems                   // problems along with their solutions
+	PermSubmit                         // submitting on behalf of any team
+	PermJudge                          // submissions, score overrides, rejudges, clarifications and announcements
+	PermManage                         // competition settings, problems, teams and members
+)
+
+// Teams have no permissions here. Handlers, which teams may use, check RoleTeam themselves and restrict the team
+// to its own data.
+var rolePermissions = map[string][]Permission{
+	RoleViewer:     {PermView},
+	RoleJudge:      {PermView, PermViewProblems, PermSubmit, PermJudge},
+	RoleOrganiser:  {PermView, PermViewProblems, PermSubmit, PermJudge, PermManage},
+	RoleSuperadmin: {PermView, PermViewProblems, PermSubmit, PermJudge, PermManage},
+}
+
+// AssignableRoles can be given to users through competition membership.
+var AssignableRoles = []string{RoleOrganiser, RoleJudge, RoleViewer}
+
+func can(role string, permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
+
+// authenticate returns the logged-in user. If the token is invalid, the response is written and false is returned.
+func (server *httpImpl) authenticate(w http.ResponseWriter, r *http.Request) (db.User, bool) {
+	user, err := server.db.CheckToken(GetToken(r))
+	if err != nil {
+		WriteForbiddenJWT(w)
+		return user, false
+	}
+	return user, true
+}
+
+// competitionRole returns the user's role in the competition.
+func (server *httpImpl) competitionRole(user db.User, competitionId string) (string, error) {
+	if user.IsAdmin {
+		return RoleSuperadmin, nil
+	}
+
+	if user.TeamID != "" {
+		team, err := server.db.GetTeam(user.TeamID)
+		if err != nil {
+			return RoleNone, err
+		}
+		if team.CompetitionID == competitionId {
+			return RoleTeam, nil
+		}
+		return RoleNone, nil
+	}
+
+	member, err := server.db.GetCompetitionMember(competitionId, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RoleNone, nil
+	}
+	if err != nil {
+		return RoleNone, err
+	}
+	return member.Role, nil
+}
+
+// authorize checks whether the user has the permission in the competition. If not, the response is written and
+// false is returned.
+func (server *httpImpl) authorize(w http.ResponseWriter, user db.User, competitionId string, permission Permission) bool {
+	role, err := server.competitionRole(user, competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst checking permissions"}, http.StatusInternalServerError)
+		return false
+	}
+	if !can(role, permission) {
+		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// authorizeSuperadmin is used for actions, which aren't bound to a single competition.
+func authorizeSuperadmin(w http.ResponseWriter, user db.User) bool {
+	if !user.IsAdmin {
+		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// authorizeTeam is used by handlers, which teams may use too. If the user is a team of the competition, the team is
+// returned and the competition has to be running. Otherwise the user needs the permission and the team is nil.
+func (server *httpImpl) authorizeTeam(w http.ResponseWriter, user db.User, competitionId string, permission Permission) (*db.Team, bool) {
+	role, err := server.competitionRole(user, competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst checking permissions"}, http.StatusInternalServerError)
+		return nil, false
+	}
+	if role == RoleTeam {
+		team, _, err := server.contestant(user)
+		if err != nil {
+			writeContestantError(w, err)
+			return nil, false
+		}
+		return &team, true
+	}
+	if !can(role, permission) {
+		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+		return nil, false
+	}
+	return nil, true
+}
@@ -0,0 +1,121 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/bundle"
+	"bytes"
+	"fmt"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+// maxBundleSize limits the size of uploaded bundles to 64 MiB.
+const maxBundleSize = 64 << 20
+
+// ExportCompetition downloads the competition with its problems, attachments, teams and submissions as a zip bundle.
+func (server *httpImpl) ExportCompetition(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	if !server.authorize(w, user, competitionId, PermManage) {
+		return
+	}
+
+	b, err := bundle.Export(server.db, server.blobs, competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst exporting competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	// arhiv najprej zgradimo v pomnilniku, da lahko ob napaki še vrnemo JSON
+	var buffer bytes.Buffer
+	err = b.Write(&buffer)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst writing the bundle"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "export", "competition", competitionId, competitionId, nil, b.Manifest)
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"competition-%s.zip\"", competitionId))
+	w.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// ImportCompetition imports a bundle, uploaded as the bundle file. With dry_run only the validation report is returned.
+// conflict decides what happens, if the competition already exists (fail, new or replace).
+func (server *httpImpl) ImportCompetition(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	if !authorizeSuperadmin(w, user) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBundleSize)
+	file, _, err := r.FormFile("bundle")
+	if err != nil {
+		WriteJSON(w, Response{Error: "Bundle is missing or too large"}, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst reading the bundle"}, http.StatusInternalServerError)
+		return
+	}
+
+	b, err := bundle.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		WriteJSON(w, Response{Error: "Bundle is invalid", Data: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	options := bundle.Options{Conflict: r.FormValue("conflict")}
+	if options.Conflict != "" && !slices.Contains(bundle.Conflicts, options.Conflict) {
+		WriteJSON(w, Response{Error: "Conflict is invalid. Expected fail, new or replace."}, http.StatusBadRequest)
+		return
+	}
+	if r.FormValue("dry_run") != "" {
+		options.DryRun, err = strconv.ParseBool(r.FormValue("dry_run"))
+		if err != nil {
+			WriteJSON(w, Response{Error: "Dry_run is invalid. Expected a boolean."}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := bundle.Import(server.db, server.blobs, b, options)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst importing competition", Data: report}, http.StatusInternalServerError)
+		return
+	}
+
+	if len(report.Errors) != 0 {
+		status := http.StatusBadRequest
+		if report.Conflict && (options.Conflict == "" || options.Conflict == bundle.ConflictFail) {
+			status = http.StatusConflict
+		}
+		WriteJSON(w, Response{Error: "Bundle can't be imported", Data: report}, status)
+		return
+	}
+
+	if options.DryRun {
+		WriteJSON(w, Response{Data: report}, http.StatusOK)
+		return
+	}
+
+	server.audit(user, "import", "competition", report.CompetitionID, report.CompetitionID, nil, report)
+	server.leaderboards.invalidate(report.CompetitionID)
+
+	WriteJSON(w, Response{Data: report}, http.StatusCreated)
+}
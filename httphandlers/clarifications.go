@@ -0,0 +1,354 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxClarificationLength = 5000
+
+// competitionProblem checks, that the problem belongs to the competition. An empty problem ID refers to the whole competition.
+func (server *httpImpl) competitionProblem(competitionId string, problemId string) (bool, error) {
+	if problemId == "" {
+		return true, nil
+	}
+	problem, err := server.db.GetProblem(problemId)
+	if err != nil {
+		return false, err
+	}
+	return problem.CompetitionID == competitionId, nil
+}
+
+func (server *httpImpl) GetClarifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	teamId := r.URL.Query().Get("team_id")
+
+	team, ok := server.authorizeTeam(w, user, competitionId, PermJudge)
+	if !ok {
+		return
+	}
+	// ekipa vidi svoja in javna vprašanja
+	if team != nil {
+		teamId = team.ID
+	}
+
+	var clarifications []db.Clarification
+	var err error
+	if teamId == "" {
+		clarifications, err = server.db.GetClarificationsForCompetition(competitionId)
+	} else {
+		clarifications, err = server.db.GetTeamClarifications(competitionId, teamId)
+	}
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching clarifications"}, http.StatusInternalServerError)
+		return
+	}
+
+	if clarifications == nil {
+		clarifications = make([]db.Clarification, 0)
+	}
+
+	WriteJSON(w, Response{Data: clarifications}, http.StatusOK)
+}
+
+// NewClarification asks a question. Judges ask on behalf of the given team, teams only for themselves.
+func (server *httpImpl) NewClarification(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	question := strings.TrimSpace(r.FormValue("question"))
+	if question == "" || len(question) > maxClarificationLength {
+		WriteJSON(w, Response{Error: "Invalid question"}, http.StatusBadRequest)
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	competition, err := server.db.GetCompetition(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	contestant, ok := server.authorizeTeam(w, user, competition.ID, PermSubmit)
+	if !ok {
+		return
+	}
+	teamId := r.FormValue("team_id")
+	if contestant != nil {
+		teamId = contestant.ID
+	}
+
+	team, err := server.db.GetTeam(teamId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching team"}, http.StatusInternalServerError)
+		return
+	}
+
+	if team.CompetitionID != competition.ID {
+		WriteJSON(w, Response{Error: "Team doesn't compete in this competition"}, http.StatusBadRequest)
+		return
+	}
+
+	problemId := r.FormValue("problem_id")
+	valid, err := server.competitionProblem(competition.ID, problemId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching problem"}, http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		WriteJSON(w, Response{Error: "Problem doesn't belong to this competition"}, http.StatusBadRequest)
+		return
+	}
+
+	clarification := db.Clarification{
+		ID:            uuid.NewString(),
+		CompetitionID: competition.ID,
+		ProblemID:     problemId,
+		TeamID:        team.ID,
+		Question:      question,
+		AskedBy:       user.ID,
+	}
+
+	err = server.db.InsertClarification(clarification)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst inserting clarification"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "create", "clarification", clarification.ID, competition.ID, nil, clarification)
+
+	marshal, err := json.Marshal(WSNewClarification{
+		MessageType:   4,
+		Clarification: clarification.ID,
+		CompetitionID: competition.ID,
+		TeamID:        team.ID,
+		TeamName:      team.Name,
+		ProblemID:     problemId,
+		Question:      question,
+	})
+	if err == nil {
+		server.hub.broadcast <- Message{CompetitionID: competition.ID, Data: marshal}
+	}
+
+	WriteJSON(w, Response{Data: clarification.ID}, http.StatusCreated)
+}
+
+// AnswerClarification answers the clarification. If public is set, the answer is broadcast to all teams.
+func (server *httpImpl) AnswerClarification(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	clarification, err := server.db.GetClarification(mux.Vars(r)["clarification_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching clarification"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, clarification.CompetitionID, PermJudge) {
+		return
+	}
+	before := clarification
+
+	answer := strings.TrimSpace(r.FormValue("answer"))
+	if answer == "" || len(answer) > maxClarificationLength {
+		WriteJSON(w, Response{Error: "Invalid answer"}, http.StatusBadRequest)
+		return
+	}
+
+	public, err := strconv.ParseBool(r.FormValue("public"))
+	if err == nil {
+		clarification.Public = public
+	}
+
+	clarification.Answer = answer
+	clarification.AnsweredBy = user.ID
+
+	err = server.db.UpdateClarification(clarification)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst updating clarification"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "answer", "clarification", clarification.ID, clarification.CompetitionID, before, clarification)
+
+	marshal, err := json.Marshal(WSClarificationAnswer{
+		MessageType:   5,
+		Clarification: clarification.ID,
+		CompetitionID: clarification.CompetitionID,
+		TeamID:        clarification.TeamID,
+		ProblemID:     clarification.ProblemID,
+		Question:      clarification.Question,
+		Answer:        clarification.Answer,
+		Public:        clarification.Public,
+	})
+	if err == nil {
+		// zasebni odgovor dobi samo ekipa, ki je vprašala, javnega vse ekipe in javna lestvica, če je objavljena
+		if clarification.Public {
+			server.broadcastToTeams(clarification.CompetitionID, marshal)
+		} else {
+			server.hub.broadcast <- Message{CompetitionID: clarification.CompetitionID, TeamID: clarification.TeamID, Data: marshal}
+		}
+	}
+
+	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+}
+
+func (server *httpImpl) DeleteClarification(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	clarification, err := server.db.GetClarification(mux.Vars(r)["clarification_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching clarification"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, clarification.CompetitionID, PermJudge) {
+		return
+	}
+
+	err = server.db.DeleteClarification(clarification.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst deleting clarification"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "delete", "clarification", clarification.ID, clarification.CompetitionID, clarification, nil)
+
+	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+}
+
+// GetAnnouncements returns all announcements of the competition, so that clients, which connect later, can catch up.
+func (server *httpImpl) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+
+	if _, ok := server.authorizeTeam(w, user, competitionId, PermView); !ok {
+		return
+	}
+
+	announcements, err := server.db.GetAnnouncementsForCompetition(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching announcements"}, http.StatusInternalServerError)
+		return
+	}
+
+	if announcements == nil {
+		announcements = make([]db.Announcement, 0)
+	}
+
+	WriteJSON(w, Response{Data: announcements}, http.StatusOK)
+}
+
+func (server *httpImpl) NewAnnouncement(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	text := strings.TrimSpace(r.FormValue("text"))
+	if text == "" || len(text) > maxClarificationLength {
+		WriteJSON(w, Response{Error: "Invalid text"}, http.StatusBadRequest)
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	competition, err := server.db.GetCompetition(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermJudge) {
+		return
+	}
+
+	problemId := r.FormValue("problem_id")
+	valid, err := server.competitionProblem(competition.ID, problemId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching problem"}, http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		WriteJSON(w, Response{Error: "Problem doesn't belong to this competition"}, http.StatusBadRequest)
+		return
+	}
+
+	announcement := db.Announcement{
+		ID:            uuid.NewString(),
+		CompetitionID: competition.ID,
+		ProblemID:     problemId,
+		Text:          text,
+		AuthorID:      user.ID,
+	}
+
+	err = server.db.InsertAnnouncement(announcement)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst inserting announcement"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "create", "announcement", announcement.ID, competition.ID, nil, announcement)
+
+	marshal, err := json.Marshal(WSAnnouncement{
+		MessageType:   6,
+		Announcement:  announcement.ID,
+		CompetitionID: competition.ID,
+		ProblemID:     problemId,
+		Text:          text,
+		CreatedAt:     int(time.Now().Unix()),
+	})
+	if err == nil {
+		server.broadcastToTeams(competition.ID, marshal)
+	}
+
+	WriteJSON(w, Response{Data: announcement.ID}, http.StatusCreated)
+}
+
+func (server *httpImpl) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	announcement, err := server.db.GetAnnouncement(mux.Vars(r)["announcement_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching announcement"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, announcement.CompetitionID, PermJudge) {
+		return
+	}
+
+	err = server.db.DeleteAnnouncement(announcement.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst deleting announcement"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "delete", "announcement", announcement.ID, announcement.CompetitionID, announcement, nil)
+
+	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+}
@@ -0,0 +1,154 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+// clockInterval is the number of seconds between periodic clock broadcasts.
+const clockInterval = 10
+
+// Clock is the authoritative state of the contest clock. Clients compute their offset as ServerTime minus their own
+// time at receipt and count down from Remaining, instead of relying on their own clocks.
+type Clock struct {
+	CompetitionID string `json:"competition_id"`
+	ServerTime    int64  `json:"server_time"` // unix time in milliseconds
+	Status        int    `json:"status"`
+	StatusName    string `json:"status_name"`
+	StartTime     int    `json:"start_time"`
+	StartsIn      int    `json:"starts_in"` // seconds until the scheduled start, 0 if the competition isn't scheduled
+	Elapsed       int    `json:"elapsed"`   // seconds of contest time, without pauses
+	Remaining     int    `json:"remaining"` // seconds of contest time left, -1 if the competition has no planned end
+	Paused        bool   `json:"paused"`
+	Frozen        bool   `json:"frozen"`
+	FreezeTime    int    `json:"freeze_time"` // minutes of contest time, 0 if there is no freeze
+}
+
+func competitionClock(competition db.Competition, now time.Time) Clock {
+	unix := int(now.Unix())
+	clock := Clock{
+		CompetitionID: competition.ID,
+		ServerTime:    now.UnixMilli(),
+		Status:        competition.Status,
+		StatusName:    statusNames[competition.Status],
+		StartTime:     competition.StartTime,
+		Elapsed:       contestTime(competition, unix),
+		Remaining:     -1,
+		Paused:        competition.Status == db.StatusPaused,
+		FreezeTime:    competition.FreezeTime,
+	}
+
+	if competition.Status == db.StatusScheduled && competition.PlannedStart > unix {
+		clock.StartsIn = competition.PlannedStart - unix
+	}
+
+	// med premorom se odštevanje do načrtovanega konca ustavi
+	reference := unix
+	if competition.PausedAt != 0 {
+		reference = competition.PausedAt
+	}
+	if competition.StartTime == 0 && competition.PlannedStart > reference {
+		reference = competition.PlannedStart
+	}
+
+	limited := false
+	if competition.Duration != 0 {
+		clock.Remaining = competition.Duration*60 - clock.Elapsed
+		limited = true
+	}
+	if competition.PlannedEnd != 0 {
+		left := competition.PlannedEnd - reference
+		if !limited || left < clock.Remaining {
+			clock.Remaining = left
+		}
+		limited = true
+	}
+	if limited {
+		clock.Remaining = max(0, clock.Remaining)
+	}
+	if competition.Status == db.StatusFinished || competition.Status == db.StatusArchived {
+		clock.Remaining = 0
+	}
+
+	clock.Frozen = competition.FreezeTime > 0 && competition.StartTime != 0 && clock.Elapsed >= competition.FreezeTime*60
+	return clock
+}
+
+// broadcastClock sends the competition's clock to the authenticated websocket clients and, if the scoreboard is
+// public, to the public feed.
+func (server *httpImpl) broadcastClock(competition db.Competition) {
+	marshal, err := json.Marshal(WSClock{
+		MessageType: 10,
+		Clock:       competitionClock(competition, time.Now()),
+	})
+	if err != nil {
+		return
+	}
+	server.broadcastToTeams(competition.ID, marshal)
+}
+
+// tickClocks broadcasts the clocks of active competitions every clockInterval seconds and as soon as their
+// scoreboard freezes. frozen holds the freeze state from the previous tick.
+func (server *httpImpl) tickClocks(now time.Time, frozen map[string]bool) {
+	competitions, err := server.db.GetActiveCompetitions()
+	if err != nil {
+		server.logger.Errorw("failed to fetch active competitions", "error", err.Error())
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, competition := range competitions {
+		seen[competition.ID] = true
+		clock := competitionClock(competition, now)
+		changed := frozen[competition.ID] != clock.Frozen
+		frozen[competition.ID] = clock.Frozen
+		if changed || now.Unix()%clockInterval == 0 {
+			server.broadcastClock(competition)
+		}
+	}
+	for id := range frozen {
+		if !seen[id] {
+			delete(frozen, id)
+		}
+	}
+}
+
+// GetClock returns the competition's clock to anyone with a role in the competition, teams included.
+func (server *httpImpl) GetClock(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competition, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	role, err := server.competitionRole(user, competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst checking permissions"}, http.StatusInternalServerError)
+		return
+	}
+	if role == RoleNone {
+		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	WriteJSON(w, Response{Data: competitionClock(competition, time.Now())}, http.StatusOK)
+}
+
+func (server *httpImpl) GetPublicClock(w http.ResponseWriter, r *http.Request) {
+	competition, ok := server.publicCompetition(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	WriteJSON(w, Response{Data: competitionClock(competition, time.Now())}, http.StatusOK)
+}
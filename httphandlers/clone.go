@@ -0,0 +1,168 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/blobstore"
+	"HTTP-boilerplate/db"
+	"HTTP-boilerplate/scoring"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// cloneOption parses a boolean form value, which defaults to def if it isn't given.
+func cloneOption(r *http.Request, name string, def bool) (bool, error) {
+	if r.FormValue(name) == "" {
+		return def, nil
+	}
+	return strconv.ParseBool(r.FormValue(name))
+}
+
+// CloneCompetition creates a new draft competition from an existing one (usually a template). Settings and problems
+// with their attachments are copied by default, teams only if requested. Submissions, clarifications and team
+// accounts are never copied.
+func (server *httpImpl) CloneCompetition(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	source, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, source.ID, PermManage) {
+		return
+	}
+
+	copySettings, err := cloneOption(r, "settings", true)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Settings is invalid. Expected a boolean."}, http.StatusBadRequest)
+		return
+	}
+	copyProblems, err := cloneOption(r, "problems", true)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Problems is invalid. Expected a boolean."}, http.StatusBadRequest)
+		return
+	}
+	copyTeams, err := cloneOption(r, "teams", false)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Teams is invalid. Expected a boolean."}, http.StatusBadRequest)
+		return
+	}
+	isTemplate, err := cloneOption(r, "is_template", false)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Is_template is invalid. Expected a boolean."}, http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = source.Name + " (copy)"
+	}
+
+	clone := db.Competition{
+		ID:            uuid.NewString(),
+		Name:          name,
+		Status:        db.StatusDraft,
+		PenaltyEach:   1,
+		ScoringPolicy: source.ScoringPolicy,
+		IsTemplate:    isTemplate,
+	}
+	// termini se ne kopirajo, vsak krog ima svoje
+	if copySettings {
+		clone.Penalty = source.Penalty
+		clone.PenaltyEach = source.PenaltyEach
+		clone.ScoringParameters = source.ScoringParameters
+		clone.PublicScoreboard = source.PublicScoreboard
+		clone.FreezeTime = source.FreezeTime
+		clone.TieBreakers = source.TieBreakers
+		clone.Duration = source.Duration
+	} else {
+		clone.ScoringPolicy = scoring.DefaultPolicy
+	}
+
+	problems := make([]db.Problem, 0)
+	if copyProblems {
+		problems, err = server.db.GetProblemsForCompetition(source.ID)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst fetching problems"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	teams := make([]db.Team, 0)
+	if copyTeams {
+		teams, err = server.db.GetTeamsForCompetition(source.ID)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst fetching teams"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// priponke dobijo nove ključe, da izbris v eni kopiji ne izbriše datotek druge
+	attachments := make([]db.Attachment, 0)
+	sourceKeys := make([]string, 0)
+	for i := range problems {
+		problemAttachments, err := server.db.GetAttachmentsForProblem(problems[i].ID)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst fetching attachments"}, http.StatusInternalServerError)
+			return
+		}
+		problems[i].ID = uuid.NewString()
+		problems[i].CompetitionID = clone.ID
+		problems[i].AuthorID = user.ID
+		for _, v := range problemAttachments {
+			sourceKeys = append(sourceKeys, v.BlobKey)
+			v.ID = uuid.NewString()
+			v.ProblemID = problems[i].ID
+			v.CompetitionID = clone.ID
+			v.BlobKey = uuid.NewString()
+			v.UploadedBy = user.ID
+			attachments = append(attachments, v)
+		}
+	}
+	for i := range teams {
+		teams[i].ID = uuid.NewString()
+		teams[i].CompetitionID = clone.ID
+	}
+
+	// organizator, ki ni superadministrator, mora imeti dostop do kopije
+	members := make([]db.CompetitionMember, 0)
+	if !user.IsAdmin {
+		members = append(members, db.CompetitionMember{
+			ID:            uuid.NewString(),
+			CompetitionID: clone.ID,
+			UserID:        user.ID,
+			Role:          RoleOrganiser,
+		})
+	}
+
+	removeCopies := func(attachments []db.Attachment) {
+		for _, v := range attachments {
+			server.blobs.Delete(v.BlobKey)
+		}
+	}
+	for i := range attachments {
+		err = blobstore.Copy(server.blobs, sourceKeys[i], attachments[i].BlobKey)
+		if err != nil {
+			removeCopies(attachments[:i])
+			WriteJSON(w, Response{Error: "Server error whilst copying attachments"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	err = server.db.InsertCompetitionCopy(clone, problems, attachments, teams, members)
+	if err != nil {
+		removeCopies(attachments)
+		WriteJSON(w, Response{Error: "Server error whilst inserting competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "clone", "competition", clone.ID, clone.ID, source, clone)
+
+	WriteJSON(w, Response{Data: clone.ID}, http.StatusCreated)
+}
@@ -2,23 +2,78 @@ package httphandlers
 
 import (
 	"HTTP-boilerplate/db"
+	"HTTP-boilerplate/scoring"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
-func (server *httpImpl) GetCompetitions(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
+// competitionScorer returns the competition's scoring policy along with its parameters. Time penalties are
+// always taken from the competition's Penalty and PenaltyEach.
+func competitionScorer(competition db.Competition) (scoring.Scorer, scoring.Parameters, error) {
+	scorer, err := scoring.Get(competition.ScoringPolicy)
 	if err != nil {
-		WriteForbiddenJWT(w)
-		return
+		return nil, scoring.Parameters{}, err
+	}
+	parameters, err := scoring.ParseParameters(competition.ScoringParameters)
+	if err != nil {
+		return nil, scoring.Parameters{}, err
+	}
+	parameters.TimePenalty = competition.Penalty
+	parameters.TimePenaltyEach = competition.PenaltyEach
+	return scorer, parameters, parameters.Validate()
+}
+
+// parseScoring validates the scoring_policy, scoring_parameters and tie_breakers form values and stores them into the
+// competition.
+func parseScoring(r *http.Request, competition *db.Competition) error {
+	if _, ok := r.Form["scoring_policy"]; ok {
+		competition.ScoringPolicy = r.FormValue("scoring_policy")
+	}
+	if _, ok := r.Form["scoring_parameters"]; ok {
+		// parametri se prekrijejo čez obstoječe
+		parameters, err := scoring.ParseParameters(competition.ScoringParameters)
+		if err != nil {
+			parameters = scoring.DefaultParameters()
+		}
+		// časovno kazen določata penalty in penalty_each tekmovanja, zato je tu ne sprejmemo
+		keys := make(map[string]json.RawMessage)
+		err = json.Unmarshal([]byte(r.FormValue("scoring_parameters")), &keys)
+		if err != nil {
+			return err
+		}
+		for _, v := range []string{"time_penalty", "time_penalty_each"} {
+			if _, ok := keys[v]; ok {
+				return errors.New(fmt.Sprintf("%s is set by the competition's penalty and penalty_each", v))
+			}
+		}
+		err = json.Unmarshal([]byte(r.FormValue("scoring_parameters")), &parameters)
+		if err != nil {
+			return err
+		}
+		competition.ScoringParameters = parameters.String()
+	}
+	if _, ok := r.Form["tie_breakers"]; ok {
+		tieBreakers, err := scoring.ParseTieBreakers(r.FormValue("tie_breakers"))
+		if err != nil {
+			return err
+		}
+		competition.TieBreakers = strings.Join(tieBreakers, ",")
 	}
+	_, _, err := competitionScorer(*competition)
+	return err
+}
 
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+func (server *httpImpl) GetCompetitions(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -28,22 +83,30 @@ func (server *httpImpl) GetCompetitions(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if competitions == nil {
-		competitions = make([]db.Competition, 0)
+	// ostali uporabniki vidijo samo tekmovanja, v katerih imajo vlogo
+	visible := make([]db.Competition, 0)
+	for _, v := range competitions {
+		role, err := server.competitionRole(user, v.ID)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst checking permissions"}, http.StatusInternalServerError)
+			return
+		}
+		if role != RoleNone {
+			visible = append(visible, v)
+		}
 	}
+	competitions = visible
 
 	WriteJSON(w, Response{Data: competitions}, http.StatusOK)
 }
 
 func (server *httpImpl) NewCompetition(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	if !authorizeSuperadmin(w, user) {
 		return
 	}
 
@@ -78,11 +141,44 @@ func (server *httpImpl) NewCompetition(w http.ResponseWriter, r *http.Request) {
 	id := uuid.NewString()
 
 	competition := db.Competition{
-		ID:          id,
-		Name:        name,
-		Status:      0,
-		Penalty:     penalty,
-		PenaltyEach: penaltyEach,
+		ID:            id,
+		Name:          name,
+		Status:        db.StatusDraft,
+		Penalty:       penalty,
+		PenaltyEach:   penaltyEach,
+		ScoringPolicy: scoring.DefaultPolicy,
+	}
+
+	err = parseScoring(r, &competition)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Scoring is invalid", Data: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	publicScoreboard, err := strconv.ParseBool(r.FormValue("public_scoreboard"))
+	if err == nil {
+		competition.PublicScoreboard = publicScoreboard
+	}
+
+	err = parseTemplate(r, &competition)
+	if err != nil {
+		WriteJSON(w, Response{Error: err.Error()}, http.StatusConflict)
+		return
+	}
+
+	freezeTime, err := strconv.Atoi(r.FormValue("freeze_time"))
+	if err == nil {
+		if freezeTime < 0 {
+			WriteJSON(w, Response{Error: "Freeze_time is invalid. Expected a non-negative number."}, http.StatusBadRequest)
+			return
+		}
+		competition.FreezeTime = freezeTime
+	}
+
+	err = parseSchedule(r, &competition)
+	if err != nil {
+		WriteJSON(w, Response{Error: err.Error()}, http.StatusBadRequest)
+		return
 	}
 
 	err = server.db.InsertCompetition(competition)
@@ -91,18 +187,14 @@ func (server *httpImpl) NewCompetition(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	server.audit(user, "create", "competition", competition.ID, competition.ID, nil, competition)
+
 	WriteJSON(w, Response{Data: id}, http.StatusCreated)
 }
 
 func (server *httpImpl) UpdateCompetition(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -113,17 +205,10 @@ func (server *httpImpl) UpdateCompetition(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	status, err := strconv.Atoi(r.FormValue("status"))
-	if err == nil {
-		if status < 0 || status > 2 {
-			WriteJSON(w, Response{Error: "Status is invalid. Expected integer on interval [0, 2]."}, http.StatusBadRequest)
-			return
-		}
-		if status == 1 {
-			competition.StartTime = int(time.Now().Unix())
-		}
-		competition.Status = status
+	if !server.authorize(w, user, competition.ID, PermManage) {
+		return
 	}
+	before := competition
 
 	name := r.FormValue("name")
 	if name != "" {
@@ -148,47 +233,166 @@ func (server *httpImpl) UpdateCompetition(w http.ResponseWriter, r *http.Request
 		competition.PenaltyEach = penaltyEach
 	}
 
+	err = parseScoring(r, &competition)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Scoring is invalid", Data: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	publicScoreboard, err := strconv.ParseBool(r.FormValue("public_scoreboard"))
+	if err == nil {
+		competition.PublicScoreboard = publicScoreboard
+	}
+
+	err = parseTemplate(r, &competition)
+	if err != nil {
+		WriteJSON(w, Response{Error: err.Error()}, http.StatusConflict)
+		return
+	}
+
+	freezeTime, err := strconv.Atoi(r.FormValue("freeze_time"))
+	if err == nil {
+		if freezeTime < 0 {
+			WriteJSON(w, Response{Error: "Freeze_time is invalid. Expected a non-negative number."}, http.StatusBadRequest)
+			return
+		}
+		competition.FreezeTime = freezeTime
+	}
+
+	err = parseSchedule(r, &competition)
+	if err != nil {
+		WriteJSON(w, Response{Error: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	status, err := strconv.Atoi(r.FormValue("status"))
+	if err == nil {
+		err = applyTransition(&competition, status, int(time.Now().Unix()))
+		if err != nil {
+			WriteJSON(w, Response{Error: "Status is invalid", Data: err.Error()}, http.StatusConflict)
+			return
+		}
+	}
+
 	err = server.db.UpdateCompetition(competition)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst updating competition"}, http.StatusInternalServerError)
 		return
 	}
 
+	server.audit(user, "update", "competition", competition.ID, competition.ID, before, competition)
+	if competition.Status != before.Status {
+		server.broadcastCompetitionState(competition, before.Status)
+	}
+	server.broadcastClock(competition)
+	server.publishScoreboard(competition.ID)
+
 	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
 }
 
 func (server *httpImpl) DeleteCompetition(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	competitionId := mux.Vars(r)["competition_id"]
+	competition, err := server.db.GetCompetition(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
 		return
 	}
 
-	competitionId := mux.Vars(r)["competition_id"]
+	if !server.authorize(w, user, competition.ID, PermManage) {
+		return
+	}
 
-	err = server.db.DeleteCompetition(competitionId)
+	blobKeys, err := server.db.DeleteCompetition(competition.ID)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst deleting competition"}, http.StatusInternalServerError)
 		return
 	}
+	// datoteke priponk so izbrisane šele po uspešnem brisanju, neizbrisana datoteka le zaseda prostor
+	for _, v := range blobKeys {
+		err = server.blobs.Delete(v)
+		if err != nil {
+			server.logger.Errorw("failed to delete a blob", "blob", v, "error", err.Error())
+		}
+	}
+
+	server.audit(user, "delete", "competition", competition.ID, competition.ID, competition, nil)
+	server.leaderboards.invalidate(competition.ID)
 
 	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
 }
 
 type LeaderboardProblem struct {
-	LatestSubmission  db.Submission
+	LatestSubmission  db.Submission // submission counted by the scoring policy, the latest one unless the policy aggregates attempts
 	SubmissionsBefore int
+	Pending           int // submissions, whose results are hidden by the scoreboard freeze
 }
 
 type LeaderboardTeam struct {
-	Team       db.Team
-	Problems   []*LeaderboardProblem
-	TotalScore int
+	Team            db.Team
+	Problems        []*LeaderboardProblem
+	TotalScore      int
+	Rank            int // tied teams share the rank
+	PenaltyTime     int
+	LastImprovement int // second of contest time, when the team last raised its total score
+	Attempts        int
+}
+
+func (team LeaderboardTeam) standing() scoring.Standing {
+	return scoring.Standing{
+		Score:           team.TotalScore,
+		PenaltyTime:     team.PenaltyTime,
+		LastImprovement: team.LastImprovement,
+		Attempts:        team.Attempts,
+	}
+}
+
+type submissionKey struct {
+	TeamID    string
+	ProblemID string
+}
+
+// groupSubmissions groups the submissions by team and problem, preserving their order.
+func groupSubmissions(submissions []db.Submission) map[submissionKey][]db.Submission {
+	grouped := make(map[submissionKey][]db.Submission)
+	for _, v := range submissions {
+		key := submissionKey{v.TeamID, v.ProblemID}
+		grouped[key] = append(grouped[key], v)
+	}
+	return grouped
+}
+
+// lastImprovement replays the team's submissions and returns the second of contest time, when the team reached its
+// highest total score.
+func lastImprovement(submissions []db.Submission) int {
+	sort.SliceStable(submissions, func(i, j int) bool {
+		return submissions[i].SubmittedSeconds < submissions[j].SubmittedSeconds
+	})
+	scores := make(map[string]int)
+	total, best, last := 0, 0, 0
+	for _, v := range submissions {
+		total += v.Score - scores[v.ProblemID]
+		scores[v.ProblemID] = v.Score
+		if total > best {
+			best = total
+			last = v.SubmittedSeconds
+		}
+	}
+	return last
+}
+
+// countedSubmission returns the index of the submission, which counts towards the leaderboard, along with the
+// problem's penalty time. Submissions must be of a single team and problem, ordered by submission time.
+func countedSubmission(scorer scoring.Scorer, parameters scoring.Parameters, submissions []db.Submission) (int, int) {
+	attempts := make([]scoring.Attempt, len(submissions))
+	for i, v := range submissions {
+		attempts[i] = scoring.Attempt{Verdict: v.Verdict, Score: v.Score, PenaltyTime: v.PenaltyTime, SubmittedAfter: v.SubmittedAfter}
+	}
+	return scoring.Aggregate(scorer, attempts, parameters)
 }
 
 type Leaderboard struct {
@@ -197,38 +401,51 @@ type Leaderboard struct {
 	Teams       []LeaderboardTeam
 }
 
-func (server *httpImpl) BuildCompetitionLeaderboard(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
+// buildLeaderboard builds the competition's leaderboard from the teams' public submissions, counting the one picked
+// by the scoring policy (the latest, unless the policy aggregates attempts differently) for each problem. Solutions and
+// statements of problems are always stripped, public leaderboards additionally strip the submitted solutions and
+// evaluation logs. Frozen leaderboards count submissions made after the freeze as pending instead of showing their
+// results. Teams are ranked by their total score and the competition's tie-breakers.
+func (server *httpImpl) buildLeaderboard(competition db.Competition, public bool, frozen bool) (Leaderboard, error) {
+	tieBreakers, err := scoring.ParseTieBreakers(competition.TieBreakers)
 	if err != nil {
-		WriteForbiddenJWT(w)
-		return
+		return Leaderboard{}, err
 	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
-		return
-	}
-
-	competitionId := mux.Vars(r)["competition_id"]
-	competition, err := server.db.GetCompetition(competitionId)
+	scorer, parameters, err := competitionScorer(competition)
 	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
-		return
+		return Leaderboard{}, err
 	}
 
-	problems, err := server.db.GetProblemsForCompetition(competitionId)
+	problems, err := server.db.GetProblemsForCompetition(competition.ID)
 	if err != nil {
-		return
+		return Leaderboard{}, err
 	}
 
 	if problems == nil {
 		problems = make([]db.Problem, 0)
 	}
 
-	teams, err := server.db.GetTeamsForCompetition(competitionId)
+	for i := range problems {
+		problems[i].Solution = ""
+		problems[i].AlternativeSolutions = ""
+		problems[i].MandatoryVectors = ""
+		problems[i].RowWeights = ""
+		problems[i].Statement = ""
+		problems[i].InputDescription = ""
+		problems[i].OutputDescription = ""
+	}
+
+	teams, err := server.db.GetTeamsForCompetition(competition.ID)
 	if err != nil {
-		return
+		return Leaderboard{}, err
+	}
+
+	// vse oddaje naložimo z eno poizvedbo
+	submissions, err := server.db.GetPublicSubmissionsForCompetition(competition.ID)
+	if err != nil {
+		return Leaderboard{}, err
 	}
+	grouped := groupSubmissions(submissions)
 
 	lbteams := make([]LeaderboardTeam, 0)
 
@@ -238,31 +455,98 @@ func (server *httpImpl) BuildCompetitionLeaderboard(w http.ResponseWriter, r *ht
 			Problems:   make([]*LeaderboardProblem, len(problems)),
 			TotalScore: 0,
 		}
+		history := make([]db.Submission, 0)
 		for l, problem := range problems {
-			submissions, err := server.db.GetTeamSubmissionsForProblem(team.ID, problem.ID)
-			if err != nil {
-				continue
+			submissions := grouped[submissionKey{team.ID, problem.ID}]
+			visible := submissions
+			pending := 0
+			if frozen {
+				visible = make([]db.Submission, 0, len(submissions))
+				for _, v := range submissions {
+					if isFrozen(competition, v) {
+						pending++
+						continue
+					}
+					visible = append(visible, v)
+				}
 			}
-			if submissions == nil || len(submissions) == 0 {
+			if len(visible) == 0 && pending == 0 {
 				lbteam.Problems[l] = nil
 				continue
 			}
-			lbteam.Problems[l] = &LeaderboardProblem{}
-			problems[l].Solution = ""
-			lbteam.Problems[l].LatestSubmission = submissions[len(submissions)-1]
-			lbteam.Problems[l].SubmissionsBefore = len(submissions) - 1
-			lbteam.TotalScore += submissions[len(submissions)-1].Score
+			lbteam.Problems[l] = &LeaderboardProblem{Pending: pending}
+			if len(visible) == 0 {
+				continue
+			}
+			counted, penaltyTime := countedSubmission(scorer, parameters, visible)
+			latest := visible[counted]
+			if public {
+				latest.Solution = ""
+				latest.SubmissionLog = ""
+			}
+			lbteam.Problems[l].LatestSubmission = latest
+			lbteam.Problems[l].SubmissionsBefore = counted
+			lbteam.TotalScore += latest.Score
+			lbteam.PenaltyTime += penaltyTime
+			lbteam.Attempts += counted + 1
+			history = append(history, visible[:counted+1]...)
 		}
+		lbteam.LastImprovement = lastImprovement(history)
 		lbteams = append(lbteams, lbteam)
 	}
 
-	sort.Slice(lbteams, func(i, j int) bool {
-		return lbteams[i].TotalScore > lbteams[j].TotalScore
+	sort.SliceStable(lbteams, func(i, j int) bool {
+		return scoring.Compare(lbteams[i].standing(), lbteams[j].standing(), tieBreakers) < 0
 	})
 
-	WriteJSON(w, Response{Data: Leaderboard{
+	standings := make([]scoring.Standing, len(lbteams))
+	for i, v := range lbteams {
+		standings[i] = v.standing()
+	}
+	for i, rank := range scoring.Ranks(standings, tieBreakers) {
+		lbteams[i].Rank = rank
+	}
+
+	return Leaderboard{
 		Competition: competition,
 		Problems:    problems,
 		Teams:       lbteams,
-	}}, http.StatusOK)
+	}, nil
+}
+
+func (server *httpImpl) BuildCompetitionLeaderboard(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	competition, err := server.db.GetCompetition(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermView) {
+		return
+	}
+
+	// gledalci vidijo zamrznjeno lestvico
+	role, err := server.competitionRole(user, competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst checking permissions"}, http.StatusInternalServerError)
+		return
+	}
+	view := viewFull
+	if !can(role, PermJudge) {
+		view = viewFrozen
+	}
+
+	entry, err := server.leaderboard(competition, view)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst building the leaderboard"}, http.StatusInternalServerError)
+		return
+	}
+
+	writeLeaderboard(w, r, entry, "private, no-cache")
 }
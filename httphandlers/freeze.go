@@ -0,0 +1,138 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+// Reveal is a single step of the unfreeze ceremony. Remaining is the number of results, which are still hidden.
+type Reveal struct {
+	TeamID     string
+	TeamName   string
+	ProblemID  string
+	Verdict    string
+	Score      int
+	TotalScore int
+	Remaining  int
+}
+
+// isFrozen reports whether the submission's result is hidden from frozen scoreboards.
+func isFrozen(competition db.Competition, submission db.Submission) bool {
+	return competition.FreezeTime > 0 && submission.SubmittedAfter >= competition.FreezeTime && !submission.Revealed
+}
+
+// RevealNext reveals the hidden results of the lowest ranked team, which still has any, one problem at a time.
+// Each reveal is broadcast to the authenticated websocket clients and, if the scoreboard is public, to the public feed.
+func (server *httpImpl) RevealNext(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competition, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermManage) {
+		return
+	}
+
+	if competition.Status != db.StatusFinished {
+		WriteJSON(w, Response{Error: "Results can only be revealed once the competition has finished"}, http.StatusConflict)
+		return
+	}
+
+	leaderboard, err := server.buildLeaderboard(competition, true, true)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst building the leaderboard"}, http.StatusInternalServerError)
+		return
+	}
+
+	// odkrivamo od dna lestvice navzgor, pri vsaki ekipi po vrsti nalog
+	var team *LeaderboardTeam
+	problem := -1
+	remaining := 0
+	for i := len(leaderboard.Teams) - 1; i >= 0; i-- {
+		for l, v := range leaderboard.Teams[i].Problems {
+			if v == nil || v.Pending == 0 {
+				continue
+			}
+			remaining++
+			if team == nil {
+				team = &leaderboard.Teams[i]
+				problem = l
+			}
+		}
+	}
+
+	if team == nil {
+		WriteJSON(w, Response{Error: "There are no hidden results left"}, http.StatusConflict)
+		return
+	}
+
+	problemId := leaderboard.Problems[problem].ID
+	submissions, err := server.db.GetTeamSubmissionsForProblem(team.Team.ID, problemId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching submissions"}, http.StatusInternalServerError)
+		return
+	}
+
+	for _, v := range submissions {
+		if !isFrozen(competition, v) {
+			continue
+		}
+		v.Revealed = true
+		err = server.db.UpdateSubmission(v)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst revealing a submission"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// odkrije se rezultat, ki ga šteje točkovalna politika, pri ICPC prva rešitev
+	scorer, parameters, err := competitionScorer(competition)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Invalid scoring policy of the competition", Data: err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	counted, _ := countedSubmission(scorer, parameters, submissions)
+	latest := submissions[counted]
+	previous := 0
+	if p := team.Problems[problem]; p.LatestSubmission.ID != "" {
+		previous = p.LatestSubmission.Score
+	}
+
+	reveal := Reveal{
+		TeamID:     team.Team.ID,
+		TeamName:   team.Team.Name,
+		ProblemID:  problemId,
+		Verdict:    latest.Verdict,
+		Score:      latest.Score,
+		TotalScore: team.TotalScore - previous + latest.Score,
+		Remaining:  remaining - 1,
+	}
+
+	server.audit(user, "reveal", "submission", latest.ID, competition.ID, nil, reveal)
+
+	marshal, err := json.Marshal(WSReveal{
+		MessageType:   8,
+		CompetitionID: competition.ID,
+		TeamID:        reveal.TeamID,
+		TeamName:      reveal.TeamName,
+		ProblemID:     reveal.ProblemID,
+		Verdict:       reveal.Verdict,
+		Score:         reveal.Score,
+		TotalScore:    reveal.TotalScore,
+		Remaining:     reveal.Remaining,
+	})
+	if err == nil {
+		server.broadcastToTeams(competition.ID, marshal)
+	}
+	server.publishScoreboard(competition.ID)
+
+	WriteJSON(w, Response{Data: reveal}, http.StatusOK)
+}
@@ -0,0 +1,41 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// newTestServer returns a server backed by a fresh SQLite database, with the schema and all migrations applied.
+func newTestServer(t *testing.T, config db.Config) *httpImpl {
+	t.Helper()
+	database, err := db.NewSQL("sqlite3", filepath.Join(t.TempDir(), "database.sqlite3"), zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.Init()
+	migrations, err := filepath.Glob(filepath.Join("..", "migrations", "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range migrations {
+		query, err := os.ReadFile(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := database.Exec(string(query)); err != nil {
+			t.Fatalf("migration %s: %v", v, err)
+		}
+	}
+	return &httpImpl{
+		logger: zap.NewNop().Sugar(),
+		db:     database,
+		config: config,
+		hub:    NewHub(),
+
+		leaderboards: NewLeaderboardCache(),
+	}
+}
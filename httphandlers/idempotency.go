@@ -0,0 +1,89 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultIdempotencyWindow = 24 * 60 * 60
+	maxIdempotencyKeyLength  = 255
+)
+
+var (
+	errIdempotencyKeyReused     = errors.New("idempotency key was already used for a different problem or team")
+	errIdempotencyKeyInProgress = errors.New("submission for this idempotency key is not available")
+)
+
+// idempotencyKey returns the client supplied key from the Idempotency-Key header or the idempotency_key form field.
+func idempotencyKey(r *http.Request) string {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		key = r.FormValue("idempotency_key")
+	}
+	return key
+}
+
+func (server *httpImpl) idempotencyWindow() int {
+	if server.config.IdempotencyWindow <= 0 {
+		return defaultIdempotencyWindow
+	}
+	return server.config.IdempotencyWindow
+}
+
+// claimIdempotencyKey reserves the key for the new submission. If the key was already used within the window, the
+// original submission is returned instead and nothing should be created.
+func (server *httpImpl) claimIdempotencyKey(user db.User, key string, problemId string, teamId string, submissionId string) (*db.IdempotencyKey, *db.Submission, error) {
+	err := server.db.DeleteExpiredIdempotencyKeys(int(time.Now().Unix()) - server.idempotencyWindow())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := server.db.GetIdempotencyKey(user.ID, key)
+	if err == nil {
+		submission, err := server.originalSubmission(existing, problemId, teamId)
+		return nil, submission, err
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	idempotencyKey := db.IdempotencyKey{
+		ID:           uuid.NewString(),
+		Key:          key,
+		UserID:       user.ID,
+		ProblemID:    problemId,
+		TeamID:       teamId,
+		SubmissionID: submissionId,
+	}
+	err = server.db.InsertIdempotencyKey(idempotencyKey)
+	if err != nil {
+		// ključ je medtem zasedla vzporedna zahteva
+		existing, err2 := server.db.GetIdempotencyKey(user.ID, key)
+		if err2 != nil {
+			return nil, nil, err
+		}
+		submission, err := server.originalSubmission(existing, problemId, teamId)
+		return nil, submission, err
+	}
+	return &idempotencyKey, nil, nil
+}
+
+func (server *httpImpl) originalSubmission(idempotencyKey db.IdempotencyKey, problemId string, teamId string) (*db.Submission, error) {
+	if idempotencyKey.ProblemID != problemId || idempotencyKey.TeamID != teamId {
+		return nil, errIdempotencyKeyReused
+	}
+	submission, err := server.db.GetSubmission(idempotencyKey.SubmissionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// prva zahteva še ni shranila submissiona ali pa je bil ta medtem izbrisan
+		return nil, errIdempotencyKeyInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
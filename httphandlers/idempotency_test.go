@@ -0,0 +1,66 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyWindow(t *testing.T) {
+	server := newTestServer(t, db.Config{IdempotencyWindow: 60})
+	user := db.User{ID: "user"}
+
+	claimed, original, err := server.claimIdempotencyKey(user, "key", "problem", "team", "first")
+	if err != nil || claimed == nil || original != nil {
+		t.Fatalf("first claim: claimed=%v original=%v err=%v", claimed, original, err)
+	}
+
+	// dokler submission ni shranjen, ponovitev ne more vrniti rezultata
+	_, _, err = server.claimIdempotencyKey(user, "key", "problem", "team", "second")
+	if !errors.Is(err, errIdempotencyKeyInProgress) {
+		t.Fatalf("retry before the submission was stored: got %v", err)
+	}
+
+	err = server.db.InsertSubmission(db.Submission{ID: "first", ProblemID: "problem", TeamID: "team", Verdict: "PENDING"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, original, err = server.claimIdempotencyKey(user, "key", "problem", "team", "second")
+	if err != nil || claimed != nil || original == nil || original.ID != "first" {
+		t.Fatalf("retry: claimed=%v original=%v err=%v", claimed, original, err)
+	}
+
+	_, _, err = server.claimIdempotencyKey(user, "key", "other", "team", "third")
+	if !errors.Is(err, errIdempotencyKeyReused) {
+		t.Fatalf("key reused for a different problem: got %v", err)
+	}
+
+	// ključi so vezani na uporabnika
+	claimed, _, err = server.claimIdempotencyKey(db.User{ID: "other"}, "key", "problem", "team", "fourth")
+	if err != nil || claimed == nil {
+		t.Fatalf("other user's claim: claimed=%v err=%v", claimed, err)
+	}
+
+	// po izteku okna se ključ lahko ponovno uporabi
+	err = server.db.Exec(fmt.Sprintf("UPDATE idempotency_keys SET created_at=%d WHERE user_id='user'", time.Now().Unix()-61))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, original, err = server.claimIdempotencyKey(user, "key", "other", "team", "fifth")
+	if err != nil || claimed == nil || original != nil {
+		t.Fatalf("claim after the window: claimed=%v original=%v err=%v", claimed, original, err)
+	}
+}
+
+func TestDefaultIdempotencyWindow(t *testing.T) {
+	server := &httpImpl{}
+	if server.idempotencyWindow() != defaultIdempotencyWindow {
+		t.Errorf("got window %d, want the default", server.idempotencyWindow())
+	}
+	server.config.IdempotencyWindow = 10
+	if server.idempotencyWindow() != 10 {
+		t.Errorf("got window %d, want 10", server.idempotencyWindow())
+	}
+}
@@ -1,6 +1,7 @@
 package httphandlers
 
 import (
+	"HTTP-boilerplate/blobstore"
 	"HTTP-boilerplate/db"
 	"go.uber.org/zap"
 	"net/http"
@@ -17,6 +18,10 @@ type httpImpl struct {
 	db     db.SQL
 	config db.Config
 	hub    *Hub
+	queue  *JudgeQueue
+	blobs  blobstore.Store
+
+	leaderboards *LeaderboardCache
 }
 
 type HTTP interface {
@@ -31,6 +36,8 @@ type HTTP interface {
 	NewSubmission(w http.ResponseWriter, r *http.Request)
 	UpdateSubmission(w http.ResponseWriter, r *http.Request)
 	DeleteSubmission(w http.ResponseWriter, r *http.Request)
+	GetSubmissionRevisions(w http.ResponseWriter, r *http.Request)
+	GetTeamSubmissions(w http.ResponseWriter, r *http.Request)
 
 	// competitions.go
 	GetCompetitions(w http.ResponseWriter, r *http.Request)
@@ -51,15 +58,81 @@ type HTTP interface {
 	UpdateTeam(w http.ResponseWriter, r *http.Request)
 	DeleteTeam(w http.ResponseWriter, r *http.Request)
 
+	// team-accounts.go
+	SetTeamCredentials(w http.ResponseWriter, r *http.Request)
+
+	// membership.go
+	GetMembers(w http.ResponseWriter, r *http.Request)
+	SetMember(w http.ResponseWriter, r *http.Request)
+	DeleteMember(w http.ResponseWriter, r *http.Request)
+
+	// rejudge.go
+	RejudgeProblem(w http.ResponseWriter, r *http.Request)
+	RejudgeCompetition(w http.ResponseWriter, r *http.Request)
+	GetRejudge(w http.ResponseWriter, r *http.Request)
+
+	// clarifications.go
+	GetClarifications(w http.ResponseWriter, r *http.Request)
+	NewClarification(w http.ResponseWriter, r *http.Request)
+	AnswerClarification(w http.ResponseWriter, r *http.Request)
+	DeleteClarification(w http.ResponseWriter, r *http.Request)
+	GetAnnouncements(w http.ResponseWriter, r *http.Request)
+	NewAnnouncement(w http.ResponseWriter, r *http.Request)
+	DeleteAnnouncement(w http.ResponseWriter, r *http.Request)
+
+	// audit.go
+	GetAuditLog(w http.ResponseWriter, r *http.Request)
+
+	// judge-queue.go
+	RunJudgeQueue()
+
+	// lifecycle.go
+	RunScheduler()
+
+	// clone.go
+	CloneCompetition(w http.ResponseWriter, r *http.Request)
+
+	// bundle.go
+	ExportCompetition(w http.ResponseWriter, r *http.Request)
+	ImportCompetition(w http.ResponseWriter, r *http.Request)
+
+	// team-import.go
+	ImportTeams(w http.ResponseWriter, r *http.Request)
+
+	// attachments.go
+	GetAttachments(w http.ResponseWriter, r *http.Request)
+	UploadAttachment(w http.ResponseWriter, r *http.Request)
+	DownloadAttachment(w http.ResponseWriter, r *http.Request)
+	DeleteAttachment(w http.ResponseWriter, r *http.Request)
+
+	// results-export.go
+	ExportStandings(w http.ResponseWriter, r *http.Request)
+	ExportSubmissions(w http.ResponseWriter, r *http.Request)
+
+	// clock.go
+	GetClock(w http.ResponseWriter, r *http.Request)
+	GetPublicClock(w http.ResponseWriter, r *http.Request)
+
 	// ws-client.go
 	UpgradeConnection(w http.ResponseWriter, r *http.Request)
+
+	// scoreboard.go
+	GetPublicScoreboard(w http.ResponseWriter, r *http.Request)
+	PublicScoreboardConnection(w http.ResponseWriter, r *http.Request)
+
+	// freeze.go
+	RevealNext(w http.ResponseWriter, r *http.Request)
 }
 
-func NewHTTPInterface(logger *zap.SugaredLogger, db db.SQL, config db.Config, hub *Hub) HTTP {
+func NewHTTPInterface(logger *zap.SugaredLogger, db db.SQL, config db.Config, hub *Hub, queue *JudgeQueue, blobs blobstore.Store) HTTP {
 	return &httpImpl{
 		logger: logger,
 		db:     db,
 		config: config,
 		hub:    hub,
+		queue:  queue,
+		blobs:  blobs,
+
+		leaderboards: NewLeaderboardCache(),
 	}
 }
@@ -0,0 +1,263 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	defaultJudgeWorkers  = 4
+	maxJudgingAttempts   = 3
+	judgeQueuePollPeriod = 5 * time.Second
+)
+
+// JudgeQueue feeds jobs from the durable judging_jobs table to a bounded pool of workers.
+type JudgeQueue struct {
+	workers int
+	jobs    chan db.JudgingJob
+	wake    chan struct{}
+}
+
+func NewJudgeQueue(workers int) *JudgeQueue {
+	// starejše konfiguracije števila delavcev nimajo nastavljenega
+	if workers <= 0 {
+		workers = defaultJudgeWorkers
+	}
+	return &JudgeQueue{
+		workers: workers,
+		jobs:    make(chan db.JudgingJob),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// notify wakes up the dispatcher without blocking. One pending signal is enough, as the dispatcher fetches all
+// queued jobs anyway.
+func (q *JudgeQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunJudgeQueue starts the workers and dispatches queued jobs to them. Jobs, which were running when the server
+// stopped, are queued again first, along with pending submissions, which have no job.
+func (server *httpImpl) RunJudgeQueue() {
+	fmt.Println("Running judging queue!")
+
+	err := server.db.ResetRunningJudgingJobs()
+	if err != nil {
+		server.logger.Errorw("failed to recover running judging jobs", "error", err.Error())
+	}
+
+	// oddaje, ki so ostale brez opravila, se ponovno uvrstijo v vrsto
+	orphaned, err := server.db.GetOrphanedSubmissions()
+	if err != nil {
+		server.logger.Errorw("failed to fetch pending submissions without a judging job", "error", err.Error())
+	}
+	for _, submission := range orphaned {
+		err := server.enqueueSubmission(submission)
+		if err != nil {
+			server.logger.Errorw("failed to queue a pending submission", "submission", submission.ID, "error", err.Error())
+		}
+	}
+
+	for i := 0; i < server.queue.workers; i++ {
+		go server.judgeWorker()
+	}
+
+	ticker := time.NewTicker(judgeQueuePollPeriod)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := server.db.GetQueuedJudgingJobs(server.queue.workers)
+		if err != nil {
+			server.logger.Errorw("failed to fetch queued judging jobs", "error", err.Error())
+		}
+
+		for _, job := range jobs {
+			job.Status = db.JobRunning
+			job.Attempts++
+			err := server.db.UpdateJudgingJob(job)
+			if err != nil {
+				server.logger.Errorw("failed to claim a judging job", "job", job.ID, "error", err.Error())
+				continue
+			}
+			// blokira, dokler ni prost vsaj en delavec
+			server.queue.jobs <- job
+		}
+
+		// v vrsti je morda še več opravil
+		if err == nil && len(jobs) == server.queue.workers {
+			continue
+		}
+
+		select {
+		case <-server.queue.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (server *httpImpl) judgeWorker() {
+	for job := range server.queue.jobs {
+		err := server.judgeJob(job)
+		if err == nil {
+			job.Status = db.JobDone
+			job.Error = ""
+			err = server.db.UpdateJudgingJob(job)
+			if err != nil {
+				server.logger.Errorw("failed to update a judging job", "job", job.ID, "error", err.Error())
+			}
+			continue
+		}
+
+		server.logger.Warnw("judging failed", "job", job.ID, "submission", job.SubmissionID, "attempt", job.Attempts, "error", err.Error())
+		job.Error = err.Error()
+		if job.Attempts < maxJudgingAttempts {
+			job.Status = db.JobQueued
+			server.broadcastJudgingState(job.SubmissionID, "QUEUED")
+		} else {
+			job.Status = db.JobFailed
+			server.failSubmission(job, err)
+		}
+
+		err = server.db.UpdateJudgingJob(job)
+		if err != nil {
+			server.logger.Errorw("failed to update a judging job", "job", job.ID, "error", err.Error())
+		}
+		if job.Status == db.JobQueued {
+			server.queue.notify()
+		}
+	}
+}
+
+// judgeJob judges the job's submission and stores the result. Panics in the judging pipeline are returned as errors,
+// so that a single malformed submission can't take the worker down.
+func (server *httpImpl) judgeJob(job db.JudgingJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint("judging panicked: ", r))
+		}
+	}()
+
+	submission, err := server.db.GetSubmission(job.SubmissionID)
+	if err != nil {
+		return err
+	}
+	// sodnik je oddajo medtem morda ocenil ročno
+	if submission.Verdict != "PENDING" {
+		return nil
+	}
+	server.broadcastJudgingState(submission.ID, "JUDGING")
+
+	problem, err := server.db.GetProblem(submission.ProblemID)
+	if err != nil {
+		return err
+	}
+	competition, err := server.db.GetCompetition(submission.CompetitionID)
+	if err != nil {
+		return err
+	}
+	past, err := server.db.GetPastSubmissionsForProblem(submission.SubmittedSeconds, submission.TeamID, submission.ProblemID)
+	if err != nil {
+		return err
+	}
+
+	err = judgeSubmission(&submission, problem, competition, len(past), time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+	updated, err := server.db.UpdateSubmissionResult(submission)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		server.logger.Infow("dropping the judging result of a submission, which is no longer pending", "job", job.ID, "submission", submission.ID)
+		return nil
+	}
+
+	server.broadcastJudgingState(submission.ID, "JUDGED")
+	return nil
+}
+
+// failSubmission marks the submission with the JE (judging error) verdict after the last failed attempt, unless it
+// isn't pending anymore.
+func (server *httpImpl) failSubmission(job db.JudgingJob, cause error) {
+	submission, err := server.db.GetSubmission(job.SubmissionID)
+	if err != nil {
+		// submission je bil morda izbrisan
+		server.logger.Warnw("failed to fetch a submission of a failed judging job", "job", job.ID, "error", err.Error())
+		return
+	}
+
+	submission.Verdict = "JE"
+	submission.Score = 0
+	submission.PenaltyTime = 0
+	submission.SubmissionLog = fmt.Sprintf("Judging failed after %d attempts: %s\n", job.Attempts, cause.Error())
+	updated, err := server.db.UpdateSubmissionResult(submission)
+	if err != nil {
+		server.logger.Errorw("failed to update a submission of a failed judging job", "job", job.ID, "error", err.Error())
+		return
+	}
+	if updated {
+		server.broadcastJudgingState(submission.ID, "FAILED")
+	}
+}
+
+func newJudgingJob(submissionId string) db.JudgingJob {
+	return db.JudgingJob{
+		ID:           uuid.NewString(),
+		SubmissionID: submissionId,
+		Status:       db.JobQueued,
+	}
+}
+
+// enqueueSubmission persists a judging job for the submission and wakes up the dispatcher.
+func (server *httpImpl) enqueueSubmission(submission db.Submission) error {
+	err := server.db.InsertJudgingJob(newJudgingJob(submission.ID))
+	if err != nil {
+		return err
+	}
+	server.submissionQueued(submission.ID)
+	return nil
+}
+
+// submissionQueued wakes up the dispatcher and notifies the websocket clients, once the submission's job is persisted.
+func (server *httpImpl) submissionQueued(submissionId string) {
+	server.queue.notify()
+	server.broadcastJudgingState(submissionId, "QUEUED")
+}
+
+// broadcastJudgingState sends the submission's judging state to the websocket clients. Verdict and score are
+// sent once the submission is judged.
+func (server *httpImpl) broadcastJudgingState(submissionId string, state string) {
+	message := WSJudgingState{
+		MessageType: 3,
+		Submission:  submissionId,
+		State:       state,
+	}
+
+	// brez tekmovanja ne vemo, komu sporočilo poslati
+	submission, err := server.db.GetSubmission(submissionId)
+	if err != nil {
+		return
+	}
+	message.TeamID = submission.TeamID
+	message.ProblemID = submission.ProblemID
+	if state == "JUDGED" || state == "FAILED" {
+		message.Verdict = submission.Verdict
+		message.Score = submission.Score
+	}
+
+	marshal, err := json.Marshal(message)
+	if err == nil {
+		server.hub.broadcast <- Message{CompetitionID: submission.CompetitionID, TeamID: submission.TeamID, Data: marshal}
+	}
+	if submission.Public && (state == "JUDGED" || state == "FAILED") {
+		server.publishScoreboard(submission.CompetitionID)
+	}
+}
@@ -0,0 +1,131 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/ast"
+	"HTTP-boilerplate/db"
+	"HTTP-boilerplate/judge"
+	"encoding/json"
+	"fmt"
+)
+
+// problemTask builds a judging task out of the problem's reference solutions and testing options.
+func problemTask(problem db.Problem, seed int64) (judge.Task, error) {
+	weights, err := ast.ParseRowWeights(problem.RowWeights)
+	if err != nil {
+		return judge.Task{}, err
+	}
+	return judge.Task{
+		Solutions:        problem.ReferenceSolutions(),
+		IsBaseLogicOnly:  problem.IsBaseLogicOnly,
+		SampleSize:       problem.SampleSize,
+		MandatoryVectors: ast.ParseVectors(problem.MandatoryVectors),
+		Weights:          weights,
+		Seed:             seed,
+	}, nil
+}
+
+// judgeSubmission judges and scores the submission, storing the verdict, score, log and the used scoring policy
+// into it. previousSubmissions is the number of earlier submissions of the team for the same problem. If the
+// problem is tested by sampling, the seed is stored too, so that the result can be reproduced.
+func judgeSubmission(submission *db.Submission, problem db.Problem, competition db.Competition, previousSubmissions int, seed int64) error {
+	scorer, parameters, err := competitionScorer(competition)
+	if err != nil {
+		return err
+	}
+
+	task, err := problemTask(problem, seed)
+	if err != nil {
+		return err
+	}
+	test, err := judge.Evaluate(task, submission.Solution)
+	if err != nil {
+		return err
+	}
+
+	submission.Seed = 0
+	submission.SampleSize = 0
+	if test.Sampled {
+		submission.Seed = test.Seed
+		submission.SampleSize = test.SampleSize
+	}
+
+	submission.Verdict = test.Verdict
+	submission.SubmissionLog = test.EvaluationLog
+	submission.Score = 0
+	submission.PenaltyTime = 0
+	submission.ScoringPolicy = ""
+	submission.ScoringParameters = ""
+
+	if test.Verdict == "CF" || test.Verdict == "SOL_CF" {
+		return nil
+	}
+
+	result := scorer.Score(test.ScoringInput(problem.Points, previousSubmissions, submission.SubmittedAfter), parameters)
+	submission.SubmissionLog += fmt.Sprintf("Scoring policy: %s, parameters: %s.\n", scorer.Name(), parameters.String())
+	submission.SubmissionLog += result.Log
+	submission.ScoringPolicy = scorer.Name()
+	submission.ScoringParameters = parameters.String()
+	submission.PenaltyTime = result.PenaltyTime
+	submission.Score = result.Score
+	return nil
+}
+
+// broadcastSubmissionStatus sends the submission's verdict and score, along with the team's new total score, to
+// the websocket clients. Nothing is sent, if the submission isn't the team's latest public submission for the problem.
+func (server *httpImpl) broadcastSubmissionStatus(submission db.Submission, team db.Team, problem db.Problem) error {
+	// lestvica se lahko spremeni tudi, če submission ni zadnji (npr. čas zadnjega izboljšanja)
+	defer server.publishScoreboard(submission.CompetitionID)
+
+	teamSubmissions, err := server.db.GetTeamSubmissions(team.ID)
+	if err != nil {
+		return err
+	}
+	public := make([]db.Submission, 0, len(teamSubmissions))
+	for _, v := range teamSubmissions {
+		if v.Public {
+			public = append(public, v)
+		}
+	}
+	grouped := groupSubmissions(public)
+
+	// posodobljen submission ni zadnji! Ne pošlji sporočila na klient
+	submissions1 := grouped[submissionKey{team.ID, problem.ID}]
+	if len(submissions1) == 0 || submissions1[len(submissions1)-1].ID != submission.ID {
+		return nil
+	}
+
+	totalScore := 0
+
+	problems, err := server.db.GetProblemsForCompetition(submission.CompetitionID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range problems {
+		submissions := grouped[submissionKey{team.ID, v.ID}]
+		if len(submissions) == 0 {
+			continue
+		}
+		totalScore += submissions[len(submissions)-1].Score
+	}
+
+	// v teoriji lahko posodobimo tudi ekipo, čeprav niti ne
+	// "futureproofing"
+	marshal, err := json.Marshal(WSUpdateSubmissionStatus{
+		MessageType:       1,
+		Submission:        submission.ID,
+		TeamName:          team.Name,
+		TeamID:            team.ID,
+		ProblemID:         problem.ID,
+		ProblemName:       problem.Name,
+		Verdict:           submission.Verdict,
+		Score:             submission.Score,
+		MaxScore:          problem.Points,
+		TotalScore:        totalScore,
+		SubmissionsBefore: len(submissions1) - 1,
+	})
+	if err == nil {
+		server.hub.broadcast <- Message{CompetitionID: submission.CompetitionID, TeamID: submission.TeamID, Data: marshal}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultLeaderboardCache = 10
+
+type leaderboardView int
+
+const (
+	viewFull   leaderboardView = iota // every result, for judges
+	viewFrozen                        // results after the freeze are pending
+	viewPublic                        // frozen, without submitted solutions and evaluation logs
+)
+
+type leaderboardCacheKey struct {
+	competitionId string
+	view          leaderboardView
+}
+
+type cachedLeaderboard struct {
+	leaderboard Leaderboard
+	body        []byte // marshalled Response
+	etag        string
+	expires     int64
+}
+
+// LeaderboardCache holds the built leaderboards, so that every refresh doesn't rebuild the standings. Entries are
+// invalidated on every event, which may change the standings, and expire as a safety net. Every invalidation
+// increases the competition's generation, so that a leaderboard built before it isn't stored afterwards.
+type LeaderboardCache struct {
+	mu          sync.Mutex
+	entries     map[leaderboardCacheKey]cachedLeaderboard
+	generations map[string]uint64
+}
+
+func NewLeaderboardCache() *LeaderboardCache {
+	return &LeaderboardCache{
+		entries:     make(map[leaderboardCacheKey]cachedLeaderboard),
+		generations: make(map[string]uint64),
+	}
+}
+
+func (c *LeaderboardCache) get(key leaderboardCacheKey) (cachedLeaderboard, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || entry.expires <= time.Now().Unix() {
+		return entry, false
+	}
+	return entry, true
+}
+
+// generation returns the competition's current generation. It has to be read before the leaderboard is built.
+func (c *LeaderboardCache) generation(competitionId string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[competitionId]
+}
+
+// set stores the entry, unless the competition was invalidated since the given generation.
+func (c *LeaderboardCache) set(key leaderboardCacheKey, entry cachedLeaderboard, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generations[key.competitionId] != generation {
+		return
+	}
+	c.entries[key] = entry
+}
+
+// invalidate drops every view of the competition's leaderboard.
+func (c *LeaderboardCache) invalidate(competitionId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generations[competitionId]++
+	for key := range c.entries {
+		if key.competitionId == competitionId {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (server *httpImpl) leaderboardCacheTTL() int {
+	if server.config.ScoreboardCache <= 0 {
+		return defaultLeaderboardCache
+	}
+	return server.config.ScoreboardCache
+}
+
+// leaderboard returns the competition's leaderboard, either from the cache or freshly built.
+func (server *httpImpl) leaderboard(competition db.Competition, view leaderboardView) (cachedLeaderboard, error) {
+	key := leaderboardCacheKey{competition.ID, view}
+	entry, ok := server.leaderboards.get(key)
+	if ok {
+		return entry, nil
+	}
+
+	// tekmovanje je lahko razveljavljeno, medtem ko lestvico še gradimo
+	generation := server.leaderboards.generation(competition.ID)
+	leaderboard, err := server.buildLeaderboard(competition, view == viewPublic, view != viewFull)
+	if err != nil {
+		return entry, err
+	}
+	body, err := json.Marshal(Response{Data: leaderboard})
+	if err != nil {
+		return entry, err
+	}
+
+	entry = cachedLeaderboard{
+		leaderboard: leaderboard,
+		body:        body,
+		etag:        fmt.Sprintf("\"%x\"", sha256.Sum256(body)),
+		expires:     time.Now().Unix() + int64(server.leaderboardCacheTTL()),
+	}
+	server.leaderboards.set(key, entry, generation)
+	return entry, nil
+}
+
+// etagMatches checks the If-None-Match header against the ETag.
+func etagMatches(header string, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == etag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeLeaderboard writes the cached leaderboard, or 304 if the client already has it.
+func writeLeaderboard(w http.ResponseWriter, r *http.Request, entry cachedLeaderboard, cacheControl string) {
+	w.Header().Set("ETag", entry.etag)
+	w.Header().Set("Cache-Control", cacheControl)
+	if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(entry.body)
+}
@@ -0,0 +1,236 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const schedulerInterval = time.Second
+
+var statusNames = map[int]string{
+	db.StatusDraft:     "draft",
+	db.StatusRunning:   "running",
+	db.StatusFinished:  "finished",
+	db.StatusScheduled: "scheduled",
+	db.StatusPaused:    "paused",
+	db.StatusArchived:  "archived",
+}
+
+// transitions lists the states, into which a competition can move from each state.
+var transitions = map[int][]int{
+	db.StatusDraft:     {db.StatusScheduled, db.StatusRunning},
+	db.StatusScheduled: {db.StatusDraft, db.StatusRunning},
+	db.StatusRunning:   {db.StatusPaused, db.StatusFinished},
+	db.StatusPaused:    {db.StatusRunning, db.StatusFinished},
+	db.StatusFinished:  {db.StatusArchived},
+	db.StatusArchived:  {db.StatusFinished},
+}
+
+// schedulerUser is recorded in the audit log for automatic transitions.
+var schedulerUser = db.User{Username: "scheduler"}
+
+// contestTime returns the seconds of contest time, which passed until now. Pauses aren't counted.
+func contestTime(competition db.Competition, now int) int {
+	if competition.StartTime == 0 {
+		return 0
+	}
+	end := now
+	if competition.EndTime != 0 {
+		end = competition.EndTime
+	}
+	paused := competition.PausedTime
+	if competition.PausedAt != 0 {
+		paused += end - competition.PausedAt
+	}
+	return max(0, end-competition.StartTime-paused)
+}
+
+// applyTransition moves the competition into the new state and updates its times.
+func applyTransition(competition *db.Competition, status int, now int) error {
+	if status == competition.Status {
+		return nil
+	}
+	if competition.IsTemplate {
+		return errors.New("templates can't change their status")
+	}
+	if _, ok := statusNames[status]; !ok {
+		return errors.New(fmt.Sprintf("unknown status %d", status))
+	}
+	if !slices.Contains(transitions[competition.Status], status) {
+		return errors.New(fmt.Sprintf("competition can't go from %s to %s", statusNames[competition.Status], statusNames[status]))
+	}
+
+	switch status {
+	case db.StatusScheduled:
+		if competition.PlannedStart <= now {
+			return errors.New("planned_start has to be in the future")
+		}
+	case db.StatusRunning:
+		if competition.Status == db.StatusPaused {
+			pause := now - competition.PausedAt
+			competition.PausedTime += pause
+			competition.PausedAt = 0
+			// načrtovan konec se zamakne za čas premora
+			if competition.PlannedEnd != 0 {
+				competition.PlannedEnd += pause
+			}
+			break
+		}
+		competition.StartTime = now
+		if competition.Status == db.StatusScheduled && competition.PlannedStart != 0 && competition.PlannedStart <= now {
+			competition.StartTime = competition.PlannedStart
+		}
+		competition.EndTime = 0
+		competition.PausedAt = 0
+		competition.PausedTime = 0
+	case db.StatusPaused:
+		competition.PausedAt = now
+	case db.StatusFinished:
+		if competition.Status == db.StatusPaused {
+			competition.PausedTime += now - competition.PausedAt
+			competition.PausedAt = 0
+		}
+		if competition.Status != db.StatusArchived {
+			competition.EndTime = now
+		}
+	}
+
+	competition.Status = status
+	return nil
+}
+
+// parseSchedule validates the planned_start, planned_end and duration form values and stores them into the competition.
+func parseSchedule(r *http.Request, competition *db.Competition) error {
+	if r.FormValue("planned_start") != "" {
+		plannedStart, err := strconv.Atoi(r.FormValue("planned_start"))
+		if err != nil || plannedStart < 0 {
+			return errors.New("planned_start is invalid. Expected a unix time.")
+		}
+		competition.PlannedStart = plannedStart
+	}
+	if r.FormValue("planned_end") != "" {
+		plannedEnd, err := strconv.Atoi(r.FormValue("planned_end"))
+		if err != nil || plannedEnd < 0 {
+			return errors.New("planned_end is invalid. Expected a unix time.")
+		}
+		competition.PlannedEnd = plannedEnd
+	}
+	if r.FormValue("duration") != "" {
+		duration, err := strconv.Atoi(r.FormValue("duration"))
+		if err != nil || duration < 0 {
+			return errors.New("duration is invalid. Expected a non-negative number of minutes.")
+		}
+		competition.Duration = duration
+	}
+	if competition.PlannedStart != 0 && competition.PlannedEnd != 0 && competition.PlannedEnd <= competition.PlannedStart {
+		return errors.New("planned_end has to be after planned_start")
+	}
+	return nil
+}
+
+// parseTemplate reads is_template. Templates don't take part in the lifecycle, so only competitions, which aren't
+// scheduled or in progress, can become one.
+func parseTemplate(r *http.Request, competition *db.Competition) error {
+	isTemplate, err := strconv.ParseBool(r.FormValue("is_template"))
+	if err != nil {
+		return nil
+	}
+	if isTemplate && !slices.Contains([]int{db.StatusDraft, db.StatusFinished, db.StatusArchived}, competition.Status) {
+		return errors.New(fmt.Sprintf("a %s competition can't be a template", statusNames[competition.Status]))
+	}
+	competition.IsTemplate = isTemplate
+	return nil
+}
+
+// nextStatus returns the state, into which the scheduler should move the competition.
+func nextStatus(competition db.Competition, now int) int {
+	switch competition.Status {
+	case db.StatusScheduled:
+		if competition.PlannedStart != 0 && now >= competition.PlannedStart {
+			return db.StatusRunning
+		}
+	case db.StatusRunning:
+		if competition.PlannedEnd != 0 && now >= competition.PlannedEnd {
+			return db.StatusFinished
+		}
+		if competition.Duration != 0 && contestTime(competition, now) >= competition.Duration*60 {
+			return db.StatusFinished
+		}
+	}
+	return competition.Status
+}
+
+// RunScheduler starts and finishes competitions according to their planned start, end and duration, and keeps
+// the clients' contest clocks in sync.
+func (server *httpImpl) RunScheduler() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	frozen := make(map[string]bool)
+	for now := range ticker.C {
+		server.schedule(int(now.Unix()))
+		server.tickClocks(now, frozen)
+	}
+}
+
+func (server *httpImpl) schedule(now int) {
+	competitions, err := server.db.GetActiveCompetitions()
+	if err != nil {
+		server.logger.Errorw("failed to fetch active competitions", "error", err.Error())
+		return
+	}
+
+	for _, competition := range competitions {
+		status := nextStatus(competition, now)
+		if status == competition.Status {
+			continue
+		}
+
+		before := competition
+		err = applyTransition(&competition, status, now)
+		if err != nil {
+			server.logger.Errorw("failed to transition a competition", "competition", competition.ID, "error", err.Error())
+			continue
+		}
+		// zapiše se le stanje, da se ne povozijo sočasne spremembe organizatorja
+		updated, err := server.db.UpdateCompetitionStatus(competition, before.Status)
+		if err != nil {
+			server.logger.Errorw("failed to update a competition", "competition", competition.ID, "error", err.Error())
+			continue
+		}
+		if !updated {
+			continue
+		}
+
+		server.audit(schedulerUser, "update", "competition", competition.ID, competition.ID, before, competition)
+		server.broadcastCompetitionState(competition, before.Status)
+		server.broadcastClock(competition)
+		server.publishScoreboard(competition.ID)
+	}
+}
+
+// broadcastCompetitionState sends the competition's new state to the authenticated websocket clients and, if the
+// scoreboard is public, to the public feed.
+func (server *httpImpl) broadcastCompetitionState(competition db.Competition, previous int) {
+	marshal, err := json.Marshal(WSCompetitionState{
+		MessageType:    9,
+		CompetitionID:  competition.ID,
+		Status:         competition.Status,
+		StatusName:     statusNames[competition.Status],
+		PreviousStatus: previous,
+		StartTime:      competition.StartTime,
+		EndTime:        competition.EndTime,
+		PlannedEnd:     competition.PlannedEnd,
+		Duration:       competition.Duration,
+		ContestTime:    contestTime(competition, int(time.Now().Unix())),
+	})
+	if err != nil {
+		return
+	}
+	server.broadcastToTeams(competition.ID, marshal)
+}
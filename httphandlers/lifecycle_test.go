@@ -0,0 +1,138 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"testing"
+)
+
+func TestTransitions(t *testing.T) {
+	competition := db.Competition{Status: db.StatusDraft, PlannedStart: 1000}
+
+	if err := applyTransition(&competition, db.StatusFinished, 100); err == nil {
+		t.Error("a draft competition was finished")
+	}
+	if err := applyTransition(&competition, db.StatusScheduled, 2000); err == nil {
+		t.Error("a competition was scheduled to start in the past")
+	}
+	if err := applyTransition(&competition, db.StatusScheduled, 100); err != nil {
+		t.Fatal(err)
+	}
+	// zamujen začetek se šteje od načrtovanega
+	if err := applyTransition(&competition, db.StatusRunning, 1010); err != nil {
+		t.Fatal(err)
+	}
+	if competition.StartTime != 1000 {
+		t.Errorf("got start time %d, want the planned start", competition.StartTime)
+	}
+	if err := applyTransition(&competition, db.StatusArchived, 1100); err == nil {
+		t.Error("a running competition was archived")
+	}
+	if err := applyTransition(&competition, 42, 1100); err == nil {
+		t.Error("an unknown status was accepted")
+	}
+
+	template := db.Competition{Status: db.StatusDraft, IsTemplate: true}
+	if err := applyTransition(&template, db.StatusRunning, 100); err == nil {
+		t.Error("a template was started")
+	}
+}
+
+func TestContestTimeAcrossPauses(t *testing.T) {
+	competition := db.Competition{Status: db.StatusRunning, StartTime: 1000, PlannedEnd: 5000}
+	if got := contestTime(competition, 1300); got != 300 {
+		t.Errorf("running: got %d, want 300", got)
+	}
+
+	if err := applyTransition(&competition, db.StatusPaused, 1300); err != nil {
+		t.Fatal(err)
+	}
+	if got := contestTime(competition, 1900); got != 300 {
+		t.Errorf("paused: got %d, want 300", got)
+	}
+
+	if err := applyTransition(&competition, db.StatusRunning, 1900); err != nil {
+		t.Fatal(err)
+	}
+	if competition.PausedTime != 600 || competition.PausedAt != 0 || competition.PlannedEnd != 5600 {
+		t.Errorf("resumed: got paused time %d, paused at %d, planned end %d", competition.PausedTime, competition.PausedAt, competition.PlannedEnd)
+	}
+	if got := contestTime(competition, 2000); got != 400 {
+		t.Errorf("resumed: got %d, want 400", got)
+	}
+
+	// konec med premorom šteje premor do konca
+	if err := applyTransition(&competition, db.StatusPaused, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyTransition(&competition, db.StatusFinished, 2500); err != nil {
+		t.Fatal(err)
+	}
+	if got := contestTime(competition, 9000); got != 400 {
+		t.Errorf("finished: got %d, want 400", got)
+	}
+}
+
+func TestNextStatus(t *testing.T) {
+	scheduled := db.Competition{Status: db.StatusScheduled, PlannedStart: 1000}
+	if nextStatus(scheduled, 999) != db.StatusScheduled || nextStatus(scheduled, 1000) != db.StatusRunning {
+		t.Error("scheduled competition didn't start at its planned start")
+	}
+	running := db.Competition{Status: db.StatusRunning, StartTime: 1000, PausedTime: 60, Duration: 10}
+	if nextStatus(running, 1659) != db.StatusRunning || nextStatus(running, 1660) != db.StatusFinished {
+		t.Error("running competition didn't finish after its duration of contest time")
+	}
+}
+
+func TestSchedulerDoesNotOverwriteEdits(t *testing.T) {
+	server := newTestServer(t, db.Config{})
+	go server.hub.Run()
+
+	competition := db.Competition{ID: "competition", Name: "Old", Status: db.StatusScheduled, PlannedStart: 1000, PenaltyEach: 1}
+	if err := server.db.InsertCompetition(competition); err != nil {
+		t.Fatal(err)
+	}
+
+	// organizator med obhodom razporejevalnika spremeni ime
+	snapshot := competition
+	competition.Name = "New"
+	if err := server.db.UpdateCompetition(competition); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyTransition(&snapshot, db.StatusRunning, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := server.db.UpdateCompetitionStatus(snapshot, db.StatusScheduled); err != nil || !updated {
+		t.Fatalf("updated=%v err=%v", updated, err)
+	}
+	stored, err := server.db.GetCompetition(competition.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Name != "New" || stored.Status != db.StatusRunning || stored.StartTime != 1000 {
+		t.Errorf("got %+v, want the new name in a running competition", stored)
+	}
+
+	// zastarel prehod ne sme povoziti novejšega stanja
+	stale := snapshot
+	stale.Status = db.StatusScheduled
+	if err := applyTransition(&stale, db.StatusDraft, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if updated, err := server.db.UpdateCompetitionStatus(stale, db.StatusScheduled); err != nil || updated {
+		t.Fatalf("stale transition: updated=%v err=%v", updated, err)
+	}
+
+	competition = stored
+	competition.Duration = 1
+	if err := server.db.UpdateCompetition(competition); err != nil {
+		t.Fatal(err)
+	}
+	server.schedule(1060)
+	stored, err = server.db.GetCompetition(competition.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != db.StatusFinished || stored.EndTime != 1060 || stored.Name != "New" {
+		t.Errorf("got %+v, want a finished competition", stored)
+	}
+}
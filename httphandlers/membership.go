@@ -0,0 +1,154 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"net/http"
+	"slices"
+)
+
+type Member struct {
+	db.CompetitionMember
+	Username string
+}
+
+func (server *httpImpl) GetMembers(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	if !server.authorize(w, user, competitionId, PermManage) {
+		return
+	}
+
+	members, err := server.db.GetCompetitionMembers(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching members"}, http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]Member, 0)
+	for _, v := range members {
+		member := Member{CompetitionMember: v}
+		u, err := server.db.GetUser(v.UserID)
+		if err == nil {
+			member.Username = u.Username
+		}
+		result = append(result, member)
+	}
+
+	WriteJSON(w, Response{Data: result}, http.StatusOK)
+}
+
+// SetMember gives the user (user_id or username) a role in the competition. If the user already is a member, the
+// role is changed.
+func (server *httpImpl) SetMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competition, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermManage) {
+		return
+	}
+
+	role := r.FormValue("role")
+	if !slices.Contains(AssignableRoles, role) {
+		WriteJSON(w, Response{Error: "Role is invalid. Expected organiser, judge or viewer."}, http.StatusBadRequest)
+		return
+	}
+
+	var target db.User
+	if r.FormValue("user_id") != "" {
+		target, err = server.db.GetUser(r.FormValue("user_id"))
+	} else {
+		target, err = server.db.GetUserByUsername(r.FormValue("username"))
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteJSON(w, Response{Error: "User doesn't exist"}, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching the user"}, http.StatusInternalServerError)
+		return
+	}
+
+	// ekipe in superadministratorji ne morejo imeti drugih vlog
+	if target.TeamID != "" || target.IsAdmin {
+		WriteJSON(w, Response{Error: "Team accounts and superadmins can't be members"}, http.StatusBadRequest)
+		return
+	}
+
+	member, err := server.db.GetCompetitionMember(competition.ID, target.ID)
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		WriteJSON(w, Response{Error: "Server error whilst fetching the member"}, http.StatusInternalServerError)
+		return
+	}
+	before := member
+
+	member.Role = role
+	if exists {
+		err = server.db.UpdateCompetitionMember(member)
+	} else {
+		member.ID = uuid.NewString()
+		member.CompetitionID = competition.ID
+		member.UserID = target.ID
+		err = server.db.InsertCompetitionMember(member)
+	}
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst saving the member"}, http.StatusInternalServerError)
+		return
+	}
+
+	if exists {
+		server.audit(user, "update", "member", member.ID, competition.ID, before, member)
+		WriteJSON(w, Response{Data: member.ID}, http.StatusOK)
+	} else {
+		server.audit(user, "create", "member", member.ID, competition.ID, nil, member)
+		WriteJSON(w, Response{Data: member.ID}, http.StatusCreated)
+	}
+}
+
+func (server *httpImpl) DeleteMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	if !server.authorize(w, user, competitionId, PermManage) {
+		return
+	}
+
+	member, err := server.db.GetCompetitionMember(competitionId, mux.Vars(r)["user_id"])
+	if errors.Is(err, sql.ErrNoRows) {
+		WriteJSON(w, Response{Error: "User isn't a member of this competition"}, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching the member"}, http.StatusInternalServerError)
+		return
+	}
+
+	err = server.db.DeleteCompetitionMember(member.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst deleting the member"}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "delete", "member", member.ID, competitionId, member, nil)
+
+	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+}
@@ -3,25 +3,81 @@ package httphandlers
 import (
 	"HTTP-boilerplate/ast"
 	"HTTP-boilerplate/db"
+	"HTTP-boilerplate/judge"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-func (server *httpImpl) GetProblems(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
+// maxStatementLength limits the statement and the input and output descriptions, in characters.
+const maxStatementLength = 100000
+
+// parseStatement stores the statement, input_description and output_description form values, which were given, into
+// the problem.
+func parseStatement(r *http.Request, problem *db.Problem) error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"statement", &problem.Statement},
+		{"input_description", &problem.InputDescription},
+		{"output_description", &problem.OutputDescription},
+	}
+	for _, v := range fields {
+		if _, ok := r.Form[v.name]; !ok {
+			continue
+		}
+		value := r.FormValue(v.name)
+		if utf8.RuneCountInString(value) > maxStatementLength {
+			return errors.New(fmt.Sprintf("%s is longer than %d characters", v.name, maxStatementLength))
+		}
+		*v.value = value
+	}
+	return nil
+}
+
+// validateTesting checks whether the problem's mandatory vectors and row weights match the inputs of the solution,
+// and whether all reference solutions are equivalent.
+func validateTesting(problem db.Problem) error {
+	task, err := problemTask(problem, 0)
 	if err != nil {
-		WriteForbiddenJWT(w)
-		return
+		return err
+	}
+	a, err := ast.BuildAST(problem.Solution)
+	if err != nil {
+		return err
+	}
+	inputs := len(*ast.BuildInputs(a))
+	for _, v := range task.MandatoryVectors {
+		if err := ast.ValidateVector(v, inputs); err != nil {
+			return err
+		}
+	}
+	err = ast.ValidateRowWeights(task.Weights, inputs)
+	if err != nil {
+		return err
 	}
+	return judge.VerifyReferences(task)
+}
 
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+func (server *httpImpl) GetProblems(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
 	competitionId := mux.Vars(r)["competition_id"]
+
+	team, ok := server.authorizeTeam(w, user, competitionId, PermViewProblems)
+	if !ok {
+		return
+	}
+
 	competition, err := server.db.GetCompetition(competitionId)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
@@ -34,18 +90,27 @@ func (server *httpImpl) GetProblems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ekipe ne smejo videti rešitev in podrobnosti testiranja, besedila nalog pa šele po začetku
+	if team != nil {
+		for i := range problems {
+			problems[i].Solution = ""
+			problems[i].AlternativeSolutions = ""
+			problems[i].MandatoryVectors = ""
+			problems[i].RowWeights = ""
+			if !statementVisible(competition) {
+				problems[i].Statement = ""
+				problems[i].InputDescription = ""
+				problems[i].OutputDescription = ""
+			}
+		}
+	}
+
 	WriteJSON(w, Response{Data: problems}, http.StatusOK)
 }
 
 func (server *httpImpl) NewProblem(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -73,6 +138,10 @@ func (server *httpImpl) NewProblem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !server.authorize(w, user, competition.ID, PermManage) {
+		return
+	}
+
 	problems, err := server.db.GetProblemsForCompetition(competition.ID)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst fetching problems"}, http.StatusInternalServerError)
@@ -103,17 +172,42 @@ func (server *httpImpl) NewProblem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sampleSize := 0
+	if r.FormValue("sample_size") != "" {
+		sampleSize, err = strconv.Atoi(r.FormValue("sample_size"))
+		if err != nil || sampleSize < 0 {
+			WriteJSON(w, Response{Error: "Invalid sample_size"}, http.StatusBadRequest)
+			return
+		}
+	}
+
 	id := uuid.NewString()
 
 	problem := db.Problem{
-		ID:              id,
-		Name:            name,
-		Solution:        solution,
-		Position:        problemPos,
-		Points:          points,
-		CompetitionID:   competition.ID,
-		AuthorID:        user.ID,
-		IsBaseLogicOnly: isBaseLogicOnly,
+		ID:                   id,
+		Name:                 name,
+		Solution:             solution,
+		AlternativeSolutions: strings.Join(ast.ParseSolutions(r.FormValue("alternative_solutions")), ";"),
+		Position:             problemPos,
+		Points:               points,
+		CompetitionID:        competition.ID,
+		AuthorID:             user.ID,
+		IsBaseLogicOnly:      isBaseLogicOnly,
+		SampleSize:           sampleSize,
+		MandatoryVectors:     strings.Join(ast.ParseVectors(r.FormValue("mandatory_vectors")), ","),
+		RowWeights:           ast.MinifyString(r.FormValue("row_weights")),
+	}
+
+	err = parseStatement(r, &problem)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Invalid statement", Data: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	err = validateTesting(problem)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Invalid testing options or reference solutions", Data: err.Error()}, http.StatusBadRequest)
+		return
 	}
 
 	err = server.db.InsertProblem(problem)
@@ -122,18 +216,15 @@ func (server *httpImpl) NewProblem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	server.audit(user, "create", "problem", problem.ID, problem.CompetitionID, nil, problem)
+	server.publishScoreboard(problem.CompetitionID)
+
 	WriteJSON(w, Response{Data: id}, http.StatusCreated)
 }
 
 func (server *httpImpl) UpdateProblem(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -144,6 +235,12 @@ func (server *httpImpl) UpdateProblem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !server.authorize(w, user, problem.CompetitionID, PermManage) {
+		return
+	}
+
+	before := problem
+
 	problems, err := server.db.GetProblemsForCompetition(problem.CompetitionID)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst fetching problems"}, http.StatusInternalServerError)
@@ -197,6 +294,40 @@ func (server *httpImpl) UpdateProblem(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	sampleSize, err := strconv.Atoi(r.FormValue("sample_size"))
+	if err == nil {
+		if sampleSize < 0 {
+			WriteJSON(w, Response{Error: "Sample size is invalid. Expected a non-negative number."}, http.StatusBadRequest)
+			return
+		}
+		problem.SampleSize = sampleSize
+	}
+
+	if _, ok := r.Form["mandatory_vectors"]; ok {
+		problem.MandatoryVectors = strings.Join(ast.ParseVectors(r.FormValue("mandatory_vectors")), ",")
+	}
+
+	if _, ok := r.Form["alternative_solutions"]; ok {
+		problem.AlternativeSolutions = strings.Join(ast.ParseSolutions(r.FormValue("alternative_solutions")), ";")
+	}
+
+	if _, ok := r.Form["row_weights"]; ok {
+		problem.RowWeights = ast.MinifyString(r.FormValue("row_weights"))
+	}
+
+	err = parseStatement(r, &problem)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Invalid statement", Data: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// rešitev ali vektorji so se lahko spremenili, zato vedno vse preverimo
+	err = validateTesting(problem)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Invalid testing options or reference solutions", Data: err.Error()}, http.StatusBadRequest)
+		return
+	}
+
 	// to naj bo na koncu, saj posodabljamo druge probleme
 	position, err := strconv.Atoi(r.FormValue("position"))
 	if err == nil {
@@ -218,18 +349,15 @@ func (server *httpImpl) UpdateProblem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	server.audit(user, "update", "problem", problem.ID, problem.CompetitionID, before, problem)
+	server.publishScoreboard(problem.CompetitionID)
+
 	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
 }
 
 func (server *httpImpl) DeleteProblem(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -240,6 +368,10 @@ func (server *httpImpl) DeleteProblem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !server.authorize(w, user, problem.CompetitionID, PermManage) {
+		return
+	}
+
 	problems, err := server.db.GetProblemsForCompetition(problem.CompetitionID)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst fetching problems"}, http.StatusInternalServerError)
@@ -254,11 +386,27 @@ func (server *httpImpl) DeleteProblem(w http.ResponseWriter, r *http.Request) {
 		server.db.UpdateProblem(v)
 	}
 
+	attachments, err := server.db.GetAttachmentsForProblem(problem.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching attachments"}, http.StatusInternalServerError)
+		return
+	}
+	for _, v := range attachments {
+		err = server.deleteAttachment(v)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst deleting attachments"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
 	err = server.db.DeleteProblem(problemId)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst deleting a problem"}, http.StatusInternalServerError)
 		return
 	}
 
+	server.audit(user, "delete", "problem", problem.ID, problem.CompetitionID, problem, nil)
+	server.publishScoreboard(problem.CompetitionID)
+
 	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
 }
@@ -0,0 +1,246 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+type RejudgeDiff struct {
+	SubmissionID string
+	TeamID       string
+	ProblemID    string
+	OldVerdict   string
+	NewVerdict   string
+	OldScore     int
+	NewScore     int
+	Changed      bool
+}
+
+type RejudgeReport struct {
+	RejudgeID string
+	Rejudged  int
+	Changed   int
+	Skipped   []string // manually judged and pending submissions, which keep their verdict
+	Results   []RejudgeDiff
+}
+
+// rejudge re-runs the judging pipeline on the submissions, records the old and new results and broadcasts
+// the changed ones. Manually judged and pending submissions are skipped. The stored seed is reused, so that sampled
+// testing gives the same vectors as before. The results are saved in a single transaction, so a failed run changes
+// nothing.
+func (server *httpImpl) rejudge(user db.User, submissions []db.Submission) (RejudgeReport, error) {
+	report := RejudgeReport{
+		RejudgeID: uuid.NewString(),
+		Skipped:   make([]string, 0),
+		Results:   make([]RejudgeDiff, 0),
+	}
+
+	problems := make(map[string]db.Problem)
+	competitions := make(map[string]db.Competition)
+	teams := make(map[string]db.Team)
+
+	rejudged := make([]db.Submission, 0)
+	rejudges := make([]db.Rejudge, 0)
+	results := make([]RejudgeDiff, 0)
+
+	for _, submission := range submissions {
+		// submissions, ki še čakajo na ocenjevanje, bo ocenila čakalna vrsta
+		if submission.Verdict == "MAN" || submission.Verdict == "PENDING" {
+			report.Skipped = append(report.Skipped, submission.ID)
+			continue
+		}
+
+		problem, ok := problems[submission.ProblemID]
+		if !ok {
+			p, err := server.db.GetProblem(submission.ProblemID)
+			if err != nil {
+				return report, err
+			}
+			problem = p
+			problems[problem.ID] = problem
+		}
+
+		competition, ok := competitions[submission.CompetitionID]
+		if !ok {
+			c, err := server.db.GetCompetition(submission.CompetitionID)
+			if err != nil {
+				return report, err
+			}
+			competition = c
+			competitions[competition.ID] = competition
+		}
+
+		past, err := server.db.GetPastSubmissionsForProblem(submission.SubmittedSeconds, submission.TeamID, submission.ProblemID)
+		if err != nil {
+			return report, err
+		}
+
+		seed := submission.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		old := submission
+		err = judgeSubmission(&submission, problem, competition, len(past), seed)
+		if err != nil {
+			return report, err
+		}
+		submission.SubmissionLog += fmt.Sprintf("Submission was rejudged by %s! Old verdict: %s (%d points), new verdict: %s (%d points).\n", user.Username, old.Verdict, old.Score, submission.Verdict, submission.Score)
+
+		rejudged = append(rejudged, submission)
+		rejudges = append(rejudges, db.Rejudge{
+			ID:            uuid.NewString(),
+			RejudgeID:     report.RejudgeID,
+			SubmissionID:  submission.ID,
+			CompetitionID: submission.CompetitionID,
+			ProblemID:     submission.ProblemID,
+			UserID:        user.ID,
+			OldVerdict:    old.Verdict,
+			NewVerdict:    submission.Verdict,
+			OldScore:      old.Score,
+			NewScore:      submission.Score,
+		})
+		results = append(results, RejudgeDiff{
+			SubmissionID: submission.ID,
+			TeamID:       submission.TeamID,
+			ProblemID:    submission.ProblemID,
+			OldVerdict:   old.Verdict,
+			NewVerdict:   submission.Verdict,
+			OldScore:     old.Score,
+			NewScore:     submission.Score,
+			Changed:      old.Verdict != submission.Verdict || old.Score != submission.Score,
+		})
+	}
+
+	err := server.db.SaveRejudge(rejudged, rejudges)
+	if err != nil {
+		return report, err
+	}
+	report.Rejudged = len(results)
+	report.Results = results
+
+	// rezultate objavimo šele, ko so shranjeni
+	for i, diff := range results {
+		if !diff.Changed {
+			continue
+		}
+		report.Changed++
+
+		submission := rejudged[i]
+		team, ok := teams[submission.TeamID]
+		if !ok {
+			t, err := server.db.GetTeam(submission.TeamID)
+			if err != nil {
+				// ekipa je bila morda izbrisana, rezultat je vseeno posodobljen
+				server.logger.Warnw("failed to fetch team of a rejudged submission", "submission", submission.ID, "error", err.Error())
+				continue
+			}
+			team = t
+			teams[team.ID] = team
+		}
+
+		err = server.broadcastSubmissionStatus(submission, team, problems[submission.ProblemID])
+		if err != nil {
+			server.logger.Warnw("failed to broadcast rejudged submission", "submission", submission.ID, "error", err.Error())
+		}
+	}
+
+	return report, nil
+}
+
+func (server *httpImpl) RejudgeProblem(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	problemId := mux.Vars(r)["problem_id"]
+	problem, err := server.db.GetProblem(problemId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching a problem"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, problem.CompetitionID, PermJudge) {
+		return
+	}
+
+	submissions, err := server.db.GetSubmissionsForProblem(problem.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching submissions"}, http.StatusInternalServerError)
+		return
+	}
+
+	report, err := server.rejudge(user, submissions)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst rejudging submissions", Data: report}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "rejudge", "problem", problem.ID, problem.CompetitionID, nil, report)
+
+	WriteJSON(w, Response{Data: report}, http.StatusOK)
+}
+
+func (server *httpImpl) RejudgeCompetition(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	competition, err := server.db.GetCompetition(competitionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermJudge) {
+		return
+	}
+
+	submissions, err := server.db.GetSubmissionsForCompetition(competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching submissions"}, http.StatusInternalServerError)
+		return
+	}
+
+	report, err := server.rejudge(user, submissions)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst rejudging submissions", Data: report}, http.StatusInternalServerError)
+		return
+	}
+
+	server.audit(user, "rejudge", "competition", competition.ID, competition.ID, nil, report)
+
+	WriteJSON(w, Response{Data: report}, http.StatusOK)
+}
+
+func (server *httpImpl) GetRejudge(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	rejudges, err := server.db.GetRejudges(mux.Vars(r)["rejudge_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching rejudge results"}, http.StatusInternalServerError)
+		return
+	}
+
+	// vsi rezultati istega ponovnega ocenjevanja pripadajo enemu tekmovanju
+	if len(rejudges) == 0 {
+		if !authorizeSuperadmin(w, user) {
+			return
+		}
+		rejudges = make([]db.Rejudge, 0)
+	} else if !server.authorize(w, user, rejudges[0].CompetitionID, PermJudge) {
+		return
+	}
+
+	WriteJSON(w, Response{Data: rejudges}, http.StatusOK)
+}
@@ -0,0 +1,266 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/spreadsheet"
+	"bytes"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultLanguage = "en"
+
+// exportHeaders holds the translated column headers and values of result exports.
+var exportHeaders = map[string]map[string]string{
+	"en": {
+		"standings":        "Standings",
+		"submissions":      "Submissions",
+		"rank":             "Rank",
+		"team":             "Team",
+		"problem":          "Problem",
+		"score":            "score",
+		"attempts":         "attempts",
+		"verdict":          "verdict",
+		"total":            "Total",
+		"penalty":          "Penalty",
+		"all_attempts":     "Attempts",
+		"last_improvement": "Last improvement",
+		"time":             "Time",
+		"submission_score": "Score",
+		"verdict_title":    "Verdict",
+		"public":           "Public",
+		"time_override":    "Time override",
+		"solution":         "Solution",
+		"pending":          "pending",
+		"yes":              "yes",
+		"no":               "no",
+	},
+	"sl": {
+		"standings":        "Lestvica",
+		"submissions":      "Oddaje",
+		"rank":             "Mesto",
+		"team":             "Ekipa",
+		"problem":          "Naloga",
+		"score":            "točke",
+		"attempts":         "poskusi",
+		"verdict":          "rezultat",
+		"total":            "Skupaj",
+		"penalty":          "Kazen",
+		"all_attempts":     "Poskusi",
+		"last_improvement": "Zadnja izboljšava",
+		"time":             "Čas",
+		"submission_score": "Točke",
+		"verdict_title":    "Rezultat",
+		"public":           "Javna",
+		"time_override":    "Ročno vnesen čas",
+		"solution":         "Rešitev",
+		"pending":          "čaka",
+		"yes":              "da",
+		"no":               "ne",
+	},
+}
+
+// exportLanguage returns the language given by the lang parameter or the Accept-Language header.
+func exportLanguage(r *http.Request) (string, bool) {
+	lang := r.URL.Query().Get("lang")
+	if lang != "" {
+		_, ok := exportHeaders[lang]
+		return lang, ok
+	}
+	for _, v := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		v = strings.ToLower(strings.TrimSpace(strings.Split(v, ";")[0]))
+		v = strings.Split(v, "-")[0]
+		if _, ok := exportHeaders[v]; ok {
+			return v, true
+		}
+	}
+	return defaultLanguage, true
+}
+
+// formatContestTime formats seconds of contest time as h:mm:ss.
+func formatContestTime(seconds int) string {
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// writeSheet writes the sheet in the requested format (csv or xlsx) as a download.
+func writeSheet(w http.ResponseWriter, r *http.Request, sheet spreadsheet.Sheet, filename string) {
+	var buffer bytes.Buffer
+	var err error
+	contentType := ""
+	switch r.URL.Query().Get("format") {
+	case "", "csv":
+		contentType = "text/csv; charset=utf-8"
+		filename += ".csv"
+		err = spreadsheet.WriteCSV(&buffer, sheet)
+	case "xlsx":
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		filename += ".xlsx"
+		err = spreadsheet.WriteXLSX(&buffer, sheet)
+	default:
+		WriteJSON(w, Response{Error: "Format is invalid. Expected csv or xlsx."}, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst writing the export"}, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buffer.Bytes())
+}
+
+// ExportStandings exports the leaderboard with per-problem scores, attempts and verdicts as CSV or XLSX. Like on the
+// leaderboard, viewers get the frozen standings.
+func (server *httpImpl) ExportStandings(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competition, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermView) {
+		return
+	}
+
+	lang, ok := exportLanguage(r)
+	if !ok {
+		WriteJSON(w, Response{Error: "Lang is invalid. Expected en or sl."}, http.StatusBadRequest)
+		return
+	}
+	t := exportHeaders[lang]
+
+	role, err := server.competitionRole(user, competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst checking permissions"}, http.StatusInternalServerError)
+		return
+	}
+
+	view := viewFrozen
+	if can(role, PermJudge) {
+		view = viewFull
+	}
+	entry, err := server.leaderboard(competition, view)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst building the leaderboard"}, http.StatusInternalServerError)
+		return
+	}
+	leaderboard := entry.leaderboard
+
+	header := []any{t["rank"], t["team"]}
+	for _, v := range leaderboard.Problems {
+		header = append(header,
+			fmt.Sprintf("%s (%s)", v.Name, t["score"]),
+			fmt.Sprintf("%s (%s)", v.Name, t["attempts"]),
+			fmt.Sprintf("%s (%s)", v.Name, t["verdict"]))
+	}
+	header = append(header, t["total"], t["penalty"], t["all_attempts"], t["last_improvement"])
+
+	rows := [][]any{header}
+	for _, team := range leaderboard.Teams {
+		row := []any{team.Rank, team.Team.Name}
+		for _, v := range team.Problems {
+			if v == nil {
+				row = append(row, "", "", "")
+				continue
+			}
+			verdict := v.LatestSubmission.Verdict
+			if v.Pending != 0 {
+				verdict = t["pending"]
+			}
+			attempts := 0
+			if v.LatestSubmission.ID != "" {
+				attempts = v.SubmissionsBefore + 1
+			}
+			row = append(row, v.LatestSubmission.Score, attempts+v.Pending, verdict)
+		}
+		row = append(row, team.TotalScore, team.PenaltyTime, team.Attempts, formatContestTime(team.LastImprovement))
+		rows = append(rows, row)
+	}
+
+	writeSheet(w, r, spreadsheet.Sheet{Name: t["standings"], Rows: rows}, "standings-"+competition.ID)
+}
+
+// ExportSubmissions exports the competition's current submissions (without superseded revisions) as CSV or XLSX.
+func (server *httpImpl) ExportSubmissions(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competition, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermJudge) {
+		return
+	}
+
+	lang, ok := exportLanguage(r)
+	if !ok {
+		WriteJSON(w, Response{Error: "Lang is invalid. Expected en or sl."}, http.StatusBadRequest)
+		return
+	}
+	t := exportHeaders[lang]
+
+	submissions, err := server.db.GetSubmissionsForCompetition(competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching submissions"}, http.StatusInternalServerError)
+		return
+	}
+	problems, err := server.db.GetProblemsForCompetition(competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching problems"}, http.StatusInternalServerError)
+		return
+	}
+	teams, err := server.db.GetTeamsForCompetition(competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching teams"}, http.StatusInternalServerError)
+		return
+	}
+
+	problemNames := make(map[string]string)
+	for _, v := range problems {
+		problemNames[v.ID] = v.Name
+	}
+	teamNames := make(map[string]string)
+	for _, v := range teams {
+		teamNames[v.ID] = v.Name
+	}
+
+	rows := [][]any{{t["time"], t["team"], t["problem"], t["verdict_title"], t["submission_score"], t["penalty"], t["public"], t["time_override"], t["solution"]}}
+	for _, v := range submissions {
+		rows = append(rows, []any{
+			formatContestTime(v.SubmittedSeconds),
+			teamNames[v.TeamID],
+			problemNames[v.ProblemID],
+			v.Verdict,
+			v.Score,
+			v.PenaltyTime,
+			yesNo(t, v.Public),
+			v.TimeOverride,
+			v.Solution,
+		})
+	}
+
+	writeSheet(w, r, spreadsheet.Sheet{Name: t["submissions"], Rows: rows}, "submissions-"+competition.ID)
+}
+
+func yesNo(t map[string]string, v bool) string {
+	if v {
+		return t["yes"]
+	}
+	return t["no"]
+}
@@ -0,0 +1,85 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+)
+
+// publishScoreboard is called whenever the competition's standings may have changed. The cached leaderboards are
+// dropped and, if the scoreboard is public, the new one is sent to the public websocket clients.
+func (server *httpImpl) publishScoreboard(competitionId string) {
+	server.leaderboards.invalidate(competitionId)
+
+	competition, err := server.db.GetCompetition(competitionId)
+	if err != nil || !competition.PublicScoreboard {
+		return
+	}
+
+	entry, err := server.leaderboard(competition, viewPublic)
+	if err != nil {
+		server.logger.Errorw("failed to build the public scoreboard", "competition", competitionId, "error", err.Error())
+		return
+	}
+
+	marshal, err := json.Marshal(WSScoreboard{
+		MessageType:   7,
+		CompetitionID: competition.ID,
+		Scoreboard:    entry.leaderboard,
+	})
+	if err == nil {
+		server.hub.broadcast <- Message{CompetitionID: competition.ID, Public: true, Data: marshal}
+	}
+}
+
+// broadcastToTeams sends the message to the authenticated clients and all teams of the competition. The public
+// scoreboard feed receives it only while the competition's scoreboard is public.
+func (server *httpImpl) broadcastToTeams(competitionId string, data []byte) {
+	competition, err := server.db.GetCompetition(competitionId)
+	if err == nil && competition.PublicScoreboard {
+		server.hub.broadcast <- Message{CompetitionID: competitionId, Data: data}
+		server.hub.broadcast <- Message{CompetitionID: competitionId, Public: true, Data: data}
+		return
+	}
+	server.hub.broadcast <- Message{CompetitionID: competitionId, Teams: true, Data: data}
+}
+
+// publicCompetition returns the competition, if its scoreboard is public. Otherwise 404 is written, so that
+// private competitions can't be discovered.
+func (server *httpImpl) publicCompetition(w http.ResponseWriter, r *http.Request) (db.Competition, bool) {
+	competition, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil || !competition.PublicScoreboard {
+		WriteJSON(w, Response{Error: "Scoreboard isn't public"}, http.StatusNotFound)
+		return competition, false
+	}
+	return competition, true
+}
+
+// GetPublicScoreboard returns the scoreboard without logging in. Only published submissions are shown and results
+// after the freeze are pending until they are revealed.
+func (server *httpImpl) GetPublicScoreboard(w http.ResponseWriter, r *http.Request) {
+	competition, ok := server.publicCompetition(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := server.leaderboard(competition, viewPublic)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst building the scoreboard"}, http.StatusInternalServerError)
+		return
+	}
+
+	writeLeaderboard(w, r, entry, fmt.Sprintf("public, max-age=%d", server.leaderboardCacheTTL()))
+}
+
+// PublicScoreboardConnection upgrades to the public websocket feed, which only receives scoreboard updates.
+func (server *httpImpl) PublicScoreboardConnection(w http.ResponseWriter, r *http.Request) {
+	competition, ok := server.publicCompetition(w, r)
+	if !ok {
+		return
+	}
+
+	server.serveWebsocket(w, r, competition.ID, true, "")
+}
@@ -3,23 +3,23 @@ package httphandlers
 import (
 	"HTTP-boilerplate/ast"
 	"HTTP-boilerplate/db"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
-func (server *httpImpl) GetSubmission(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
+const maxTimeOverrideLength = 500
 
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+func (server *httpImpl) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -30,24 +30,25 @@ func (server *httpImpl) GetSubmission(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	WriteJSON(w, Response{Data: submission}, http.StatusOK)
-}
-
-func (server *httpImpl) NewSubmission(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
+	team, ok := server.authorizeTeam(w, user, submission.CompetitionID, PermJudge)
+	if !ok {
 		return
 	}
 
-	if !user.IsAdmin {
+	// ekipa lahko vidi samo svoje oddaje
+	if team != nil && submission.TeamID != team.ID {
 		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
 		return
 	}
 
-	submittedAfter, err := strconv.Atoi(r.FormValue("submitted_after"))
-	if err != nil {
-		WriteJSON(w, Response{Error: "Submitted_after is invalid"}, http.StatusBadRequest)
+	WriteJSON(w, Response{Data: submission}, http.StatusOK)
+}
+
+// NewSubmission submits a solution. Judges submit on behalf of any team and may correct a previous submission,
+// teams submit only for themselves while their competition is running.
+func (server *httpImpl) NewSubmission(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -58,26 +59,56 @@ func (server *httpImpl) NewSubmission(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	competition, err := server.db.GetCompetition(problem.CompetitionID)
-	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+	contestant, ok := server.authorizeTeam(w, user, problem.CompetitionID, PermSubmit)
+	if !ok {
 		return
 	}
 
-	teamId := r.FormValue("team_id")
-	team, err := server.db.GetTeam(teamId)
+	competition, err := server.db.GetCompetition(problem.CompetitionID)
 	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst fetching team"}, http.StatusInternalServerError)
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
 		return
 	}
 
-	problems, err := server.db.GetPastSubmissionsForProblem(submittedAfter, team.ID, problem.ID)
-	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst fetching problems"}, http.StatusInternalServerError)
-		return
-	}
+	// čas oddaje izračuna strežnik iz ure tekmovanja, submitted_after od klienta se ne upošteva več
+	computedSeconds := contestTime(competition, int(time.Now().Unix()))
+	submittedSeconds := computedSeconds
+	timeOverride := ""
+
+	var team db.Team
+	var previousSubmission string
+
+	if contestant == nil {
+		team, err = server.db.GetTeam(r.FormValue("team_id"))
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst fetching team"}, http.StatusInternalServerError)
+			return
+		}
+
+		if team.CompetitionID != problem.CompetitionID {
+			WriteJSON(w, Response{Error: "Team doesn't compete in this competition"}, http.StatusBadRequest)
+			return
+		}
 
-	previousSubmission := r.FormValue("previous_submission_id")
+		previousSubmission = r.FormValue("previous_submission_id")
+
+		// sodniki lahko čas vnesejo ročno, npr. za oddaje na papirju, vnesene kasneje
+		if r.FormValue("submitted_seconds") != "" {
+			submittedSeconds, err = strconv.Atoi(r.FormValue("submitted_seconds"))
+			if err != nil || submittedSeconds < 0 {
+				WriteJSON(w, Response{Error: "Submitted_seconds is invalid. Expected a non-negative number."}, http.StatusBadRequest)
+				return
+			}
+			timeOverride = strings.TrimSpace(r.FormValue("time_override_reason"))
+			if timeOverride == "" || len(timeOverride) > maxTimeOverrideLength {
+				WriteJSON(w, Response{Error: "Time_override_reason is required when overriding the submission time"}, http.StatusBadRequest)
+				return
+			}
+		}
+	} else {
+		team = *contestant
+	}
+	teamId := team.ID
 
 	id := uuid.NewString()
 
@@ -87,154 +118,145 @@ func (server *httpImpl) NewSubmission(w http.ResponseWriter, r *http.Request) {
 	submission := db.Submission{
 		ID:             id,
 		Solution:       submissionS,
-		Verdict:        "",
+		Verdict:        "PENDING",
 		Score:          0,
-		SubmittedAfter: submittedAfter,
+		SubmittedAfter: submittedSeconds / 60,
 		SubmissionLog:  "",
 		CompetitionID:  problem.CompetitionID,
 		ProblemID:      problemId,
 		TeamID:         teamId,
 		Public:         false,
+		CreatedBy:      user.ID,
+
+		SubmittedSeconds: submittedSeconds,
+		TimeOverride:     timeOverride,
 	}
 
-	if previousSubmission == "" {
-		marshal, err := json.Marshal(WSNewSubmission{
-			MessageType: 0,
-			Submission:  id,
-			TeamName:    team.Name,
-			TeamID:      team.ID,
-			ProblemID:   problem.ID,
-			ProblemName: problem.Name,
-			MaxScore:    problem.Points,
-		})
-		if err == nil {
-			server.hub.broadcast <- marshal
+	var claimedKey *db.IdempotencyKey
+	if key := idempotencyKey(r); key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			WriteJSON(w, Response{Error: "Idempotency key is too long"}, http.StatusBadRequest)
+			return
 		}
-	} else {
-		marshal, err := json.Marshal(WSChangeSubmissionID{
-			MessageType:   2,
-			OldSubmission: previousSubmission,
-			NewSubmission: id,
-		})
-		if err == nil {
-			server.hub.broadcast <- marshal
+		claimed, original, err := server.claimIdempotencyKey(user, key, problem.ID, team.ID, id)
+		if errors.Is(err, errIdempotencyKeyReused) {
+			WriteJSON(w, Response{Error: "Idempotency key was already used for a different problem or team"}, http.StatusConflict)
+			return
 		}
-	}
-
-	sub, err := ast.BuildAST(submissionS)
-	if err != nil {
-		submission.SubmissionLog = err.Error()
-		submission.Verdict = "CF" // Compilation failure
-		err = server.db.InsertSubmission(submission)
-		if err != nil {
-			WriteJSON(w, Response{Error: "Server error whilst inserting submission"}, http.StatusInternalServerError)
+		if errors.Is(err, errIdempotencyKeyInProgress) {
+			WriteJSON(w, Response{Error: "Submission for this idempotency key is not available"}, http.StatusConflict)
 			return
 		}
-		WriteJSON(w, Response{Data: submission}, http.StatusCreated)
-		return
-	}
-	subL := ast.ASTLength(sub, 0)
-
-	if problem.IsBaseLogicOnly && !ast.VerifyAgainstBase(sub) {
-		submission.SubmissionLog = "AST could not verify the submission's logic against only base logic gates (AND, OR, NOT)"
-		submission.Verdict = "CF" // Compilation failure
-		err = server.db.InsertSubmission(submission)
 		if err != nil {
-			WriteJSON(w, Response{Error: "Server error whilst inserting submission"}, http.StatusInternalServerError)
+			WriteJSON(w, Response{Error: "Server error whilst checking idempotency key"}, http.StatusInternalServerError)
 			return
 		}
-		WriteJSON(w, Response{Data: submission}, http.StatusCreated)
-		return
+		if original != nil {
+			// ponovljena zahteva, vrnemo prvotni rezultat
+			w.Header().Set("Idempotent-Replayed", "true")
+			WriteJSON(w, Response{Data: original}, http.StatusCreated)
+			return
+		}
+		claimedKey = claimed
 	}
 
-	sol, err := ast.BuildAST(problem.Solution)
-	if err != nil {
-		submission.SubmissionLog = err.Error()
-		submission.Verdict = "SOL_CF" // Solution compilation failure
-		err = server.db.InsertSubmission(submission)
-		if err != nil {
-			WriteJSON(w, Response{Error: "Server error whilst inserting submission"}, http.StatusInternalServerError)
-			return
+	// ključ sprostimo, če submissiona ne ustvarimo, da lahko klient zahtevo ponovi
+	releaseKey := func() {
+		if claimedKey != nil {
+			server.db.DeleteIdempotencyKey(claimedKey.ID)
 		}
-		WriteJSON(w, Response{Data: submission}, http.StatusCreated)
-		return
 	}
-	solL := ast.ASTLength(sol, 0)
 
-	if problem.IsBaseLogicOnly && !ast.VerifyAgainstBase(sol) {
-		submission.SubmissionLog = "AST could not verify the SOLUTION's logic against only base logic gates (AND, OR, NOT)"
-		submission.Verdict = "SOL_CF" // Solution compilation failure
-		err = server.db.InsertSubmission(submission)
+	// popravek ustvari novo revizijo, prejšnja ostane shranjena za zgodovino
+	var previous db.Submission
+	if previousSubmission != "" {
+		previous, err = server.db.GetSubmission(previousSubmission)
 		if err != nil {
-			WriteJSON(w, Response{Error: "Server error whilst inserting submission"}, http.StatusInternalServerError)
+			releaseKey()
+			WriteJSON(w, Response{Error: "Server error whilst fetching previous submission"}, http.StatusInternalServerError)
 			return
 		}
-		WriteJSON(w, Response{Data: submission}, http.StatusCreated)
-		return
+		if previous.TeamID != team.ID || previous.ProblemID != problem.ID {
+			releaseKey()
+			WriteJSON(w, Response{Error: "Previous submission belongs to a different team or problem"}, http.StatusBadRequest)
+			return
+		}
+		if previous.Superseded {
+			releaseKey()
+			WriteJSON(w, Response{Error: "Previous submission was already superseded by a newer revision"}, http.StatusConflict)
+			return
+		}
+		submission.Supersedes = previous.ID
+		// popravek ohrani čas prvotne oddaje
+		if timeOverride == "" {
+			submission.SubmittedSeconds = previous.SubmittedSeconds
+			submission.SubmittedAfter = previous.SubmittedAfter
+			submission.TimeOverride = previous.TimeOverride
+		}
 	}
 
-	test, err := ast.TestDigitalSolution(sub, sol)
+	// oddaja in njeno opravilo se vstavita skupaj, da oddaja ne ostane brez ocenjevanja
+	job := newJudgingJob(submission.ID)
+	if previousSubmission != "" {
+		err = server.db.InsertSubmissionRevision(submission, previous, job)
+	} else {
+		err = server.db.InsertQueuedSubmission(submission, job)
+	}
+	if errors.Is(err, db.ErrAlreadySuperseded) {
+		releaseKey()
+		WriteJSON(w, Response{Error: "Previous submission was already superseded by a newer revision"}, http.StatusConflict)
+		return
+	}
 	if err != nil {
-		fmt.Println("Digital solution testing failed", err.Error())
+		releaseKey()
+		WriteJSON(w, Response{Error: "Server error whilst inserting submission"}, http.StatusInternalServerError)
 		return
 	}
 
-	solH := ast.HashAST(sol)
-	subH := ast.HashAST(sub)
+	server.audit(user, "create", "submission", submission.ID, submission.CompetitionID, nil, submission)
+	if timeOverride != "" {
+		server.audit(user, "override_time", "submission", submission.ID, submission.CompetitionID,
+			map[string]any{"submitted_seconds": computedSeconds},
+			map[string]any{"submitted_seconds": submittedSeconds, "reason": timeOverride})
+	}
+	// nadomeščena oddaja izgine z lestvice
+	if previous.Public {
+		server.publishScoreboard(submission.CompetitionID)
+	}
 
-	points := 0
-	if test.Verdict == "AC" {
-		if solH == subH || subL <= solL {
-			points = problem.Points
-			test.EvaluationLog += fmt.Sprintf("Equality check passed! Contestant: %s (%d, len: %d), Judge: %s (%d, len: %d).\n", submissionS, subH, subL, problem.Solution, solH, solL)
-		} else {
-			points = int(float64(problem.Points) * 0.9)
-			test.EvaluationLog += fmt.Sprintf("Applying PART verdict! Contestant: %s (%d, len: %d), Judge: %s (%d, len: %d).\n", submissionS, subH, subL, problem.Solution, solH, solL)
-			test.Verdict = "PART"
+	if previousSubmission == "" {
+		marshal, err := json.Marshal(WSNewSubmission{
+			MessageType: 0,
+			Submission:  id,
+			TeamName:    team.Name,
+			TeamID:      team.ID,
+			ProblemID:   problem.ID,
+			ProblemName: problem.Name,
+			MaxScore:    problem.Points,
+		})
+		if err == nil {
+			server.hub.broadcast <- Message{CompetitionID: submission.CompetitionID, TeamID: submission.TeamID, Data: marshal}
+		}
+	} else {
+		marshal, err := json.Marshal(WSChangeSubmissionID{
+			MessageType:   2,
+			OldSubmission: previousSubmission,
+			NewSubmission: id,
+		})
+		if err == nil {
+			server.hub.broadcast <- Message{CompetitionID: submission.CompetitionID, TeamID: submission.TeamID, Data: marshal}
 		}
-	} else if test.Verdict == "WA" {
-		// 0.72 izhaja iz tega, da so točke deljene tako:
-		// 1. del (90 % vseh točk):
-		//    - 70 % 1. dela (skupaj 63 %) gre testnim primerom
-		//    - preostalih 30 % točk prvega dela (skupaj 27 %) gre ekvivalenci vsem testnim primerom, ki v takem primeru ni zadoščena
-		// 2. del (10 % vseh točk):
-		//    - vseh 10 % gre temu, da imajo tekmovalci rešitev identično uradni
-		points = int(float64(problem.Points) * 0.63 * (float64(test.CorrectTestCases) / float64(test.WrongTestCases+test.CorrectTestCases)))
-		test.EvaluationLog += fmt.Sprintf("Wrong answer! Wrong test cases: %d, Correct test cases: %d. Applying partial points: %d!\n", test.WrongTestCases, test.CorrectTestCases, points)
-	}
-
-	newPoints := max(0, points-len(problems)*30)
-	test.EvaluationLog += fmt.Sprintf("Applying penalty of %d points due to previous submissions! Points before: %d, Points after: %d.\n", len(problems)*30, points, newPoints)
-	points = newPoints
-
-	newPoints = max(0, points-(submittedAfter*competition.Penalty))
-	test.EvaluationLog += fmt.Sprintf("Applying penalty of %d points due to time! Points before: %d, Points after: %d.\n", submittedAfter*competition.Penalty, points, newPoints)
-	points = newPoints
-
-	submission.Verdict = test.Verdict
-	submission.SubmissionLog = test.EvaluationLog
-	submission.Score = points
-
-	err = server.db.InsertSubmission(submission)
-	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst inserting submission"}, http.StatusInternalServerError)
-		return
 	}
 
-	server.db.DeleteSubmission(previousSubmission)
+	// ocenjevanje poteka asinhrono, rezultat je sporočen preko websocketa
+	server.submissionQueued(submission.ID)
 
 	WriteJSON(w, Response{Data: submission}, http.StatusCreated)
 }
 
 func (server *httpImpl) UpdateSubmission(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -245,6 +267,11 @@ func (server *httpImpl) UpdateSubmission(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !server.authorize(w, user, submission.CompetitionID, PermJudge) {
+		return
+	}
+	before := submission
+
 	//posodobi := !submission.Public
 
 	public, err := strconv.ParseBool(r.FormValue("public"))
@@ -260,7 +287,7 @@ func (server *httpImpl) UpdateSubmission(w http.ResponseWriter, r *http.Request)
 		}
 		submission.Score = score
 		submission.Verdict = "MAN"
-		submission.SubmissionLog = "Submission was judged manually!"
+		submission.SubmissionLog = fmt.Sprintf("Submission was judged manually by %s!", user.Username)
 	}
 
 	team, err := server.db.GetTeam(submission.TeamID)
@@ -281,89 +308,156 @@ func (server *httpImpl) UpdateSubmission(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	submissions1, err := server.db.GetTeamSubmissionsForProblem(team.ID, problem.ID)
+	server.audit(user, "update", "submission", submission.ID, submission.CompetitionID, before, submission)
+
+	err = server.broadcastSubmissionStatus(submission, team, problem)
 	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst fetching team submissions"}, http.StatusInternalServerError)
+		WriteJSON(w, Response{Error: "Server error whilst broadcasting submission status"}, http.StatusInternalServerError)
 		return
 	}
 
-	if len(submissions1) == 0 {
-		WriteJSON(w, Response{Error: "Server error whilst saving submission"}, http.StatusInternalServerError)
+	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+}
+
+func (server *httpImpl) DeleteSubmission(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
-	// posodobljen submission ni zadnji! Ne pošlji sporočila na klient
-	if submissions1[len(submissions1)-1].ID != submissionId {
-		WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+	submissionId := mux.Vars(r)["submission_id"]
+	submission, err := server.db.GetSubmission(submissionId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching a submission"}, http.StatusInternalServerError)
 		return
 	}
 
-	past, err := server.db.GetPastSubmissionsForProblem(submission.SubmittedAfter, team.ID, problem.ID)
+	if !server.authorize(w, user, submission.CompetitionID, PermJudge) {
+		return
+	}
+
+	err = server.db.DeleteSubmission(submission.ID)
 	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst fetching past submissions"}, http.StatusInternalServerError)
+		WriteJSON(w, Response{Error: "Server error whilst deleting a submission"}, http.StatusInternalServerError)
 		return
 	}
 
-	totalScore := 0
+	server.audit(user, "delete", "submission", submission.ID, submission.CompetitionID, submission, nil)
+	server.publishScoreboard(submission.CompetitionID)
+
+	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+}
+
+type SubmissionRevision struct {
+	Revision   int
+	CreatedBy  string // username of the user, who created the revision
+	Submission db.Submission
+}
+
+// GetSubmissionRevisions returns the whole revision chain of the submission, from the original to the latest revision.
+func (server *httpImpl) GetSubmissionRevisions(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
 
-	problems, err := server.db.GetProblemsForCompetition(submission.CompetitionID)
+	submission, err := server.db.GetSubmission(mux.Vars(r)["submission_id"])
 	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst fetching problems"}, http.StatusInternalServerError)
+		WriteJSON(w, Response{Error: "Server error whilst fetching a submission"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, submission.CompetitionID, PermJudge) {
 		return
 	}
 
-	for _, v := range problems {
-		submissions, err := server.db.GetTeamSubmissionsForProblem(team.ID, v.ID)
+	chain := []db.Submission{submission}
+	visited := map[string]bool{submission.ID: true}
+
+	// nazaj do prvotne oddaje
+	for current := submission; current.Supersedes != "" && !visited[current.Supersedes]; {
+		current, err = server.db.GetSubmission(current.Supersedes)
+		if errors.Is(err, sql.ErrNoRows) {
+			// starejše revizije so bile izbrisane
+			break
+		}
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst fetching revisions"}, http.StatusInternalServerError)
+			return
+		}
+		visited[current.ID] = true
+		chain = append([]db.Submission{current}, chain...)
+	}
+
+	// naprej do zadnje revizije
+	for current := submission; current.Superseded; {
+		current, err = server.db.GetSubmissionRevision(current.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
 		if err != nil {
-			continue
+			WriteJSON(w, Response{Error: "Server error whilst fetching revisions"}, http.StatusInternalServerError)
+			return
 		}
-		if len(submissions) == 0 {
-			continue
+		if visited[current.ID] {
+			break
 		}
-		totalScore += submissions[len(submissions)-1].Score
-	}
-
-	// v teoriji lahko posodobimo tudi ekipo, čeprav niti ne
-	// "futureproofing"
-	marshal, err := json.Marshal(WSUpdateSubmissionStatus{
-		MessageType:       1,
-		Submission:        submissionId,
-		TeamName:          team.Name,
-		TeamID:            team.ID,
-		ProblemID:         problem.ID,
-		ProblemName:       problem.Name,
-		Verdict:           submission.Verdict,
-		Score:             submission.Score,
-		MaxScore:          problem.Points,
-		TotalScore:        totalScore,
-		SubmissionsBefore: len(past),
-	})
-	if err == nil {
-		server.hub.broadcast <- marshal
+		visited[current.ID] = true
+		chain = append(chain, current)
+	}
+
+	usernames := make(map[string]string)
+	revisions := make([]SubmissionRevision, 0)
+	for i, v := range chain {
+		username, ok := usernames[v.CreatedBy]
+		if !ok && v.CreatedBy != "" {
+			u, err := server.db.GetUser(v.CreatedBy)
+			if err == nil {
+				username = u.Username
+			}
+			usernames[v.CreatedBy] = username
+		}
+		revisions = append(revisions, SubmissionRevision{
+			Revision:   i + 1,
+			CreatedBy:  username,
+			Submission: v,
+		})
 	}
 
-	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+	WriteJSON(w, Response{Data: revisions}, http.StatusOK)
 }
 
-func (server *httpImpl) DeleteSubmission(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
+// GetTeamSubmissions returns the team's current submissions along with their logs. Teams can fetch only their own.
+func (server *httpImpl) GetTeamSubmissions(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	team, err := server.db.GetTeam(mux.Vars(r)["team_id"])
 	if err != nil {
-		WriteForbiddenJWT(w)
+		WriteJSON(w, Response{Error: "Server error whilst fetching a team"}, http.StatusInternalServerError)
 		return
 	}
 
-	if !user.IsAdmin {
+	contestant, ok := server.authorizeTeam(w, user, team.CompetitionID, PermJudge)
+	if !ok {
+		return
+	}
+	if contestant != nil && contestant.ID != team.ID {
 		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
 		return
 	}
 
-	submissionId := mux.Vars(r)["submission_id"]
-
-	err = server.db.DeleteSubmission(submissionId)
+	submissions, err := server.db.GetTeamSubmissions(team.ID)
 	if err != nil {
-		WriteJSON(w, Response{Error: "Server error whilst deleting a submission"}, http.StatusInternalServerError)
+		WriteJSON(w, Response{Error: "Server error whilst fetching submissions"}, http.StatusInternalServerError)
 		return
 	}
 
-	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
+	if submissions == nil {
+		submissions = make([]db.Submission, 0)
+	}
+
+	WriteJSON(w, Response{Data: submissions}, http.StatusOK)
 }
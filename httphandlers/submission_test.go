@@ -0,0 +1,97 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"errors"
+	"testing"
+)
+
+func TestSubmissionRevisions(t *testing.T) {
+	server := newTestServer(t, db.Config{})
+
+	original := db.Submission{ID: "original", Verdict: "PENDING"}
+	if err := server.db.InsertQueuedSubmission(original, newJudgingJob(original.ID)); err != nil {
+		t.Fatal(err)
+	}
+	// delavec medtem shrani oceno
+	original.Verdict = "AC"
+	original.Score = 100
+	if updated, err := server.db.UpdateSubmissionResult(original); err != nil || !updated {
+		t.Fatalf("storing the result: updated=%v err=%v", updated, err)
+	}
+
+	stale := db.Submission{ID: "original", Verdict: "PENDING"}
+	revision := db.Submission{ID: "revision", Verdict: "PENDING", Supersedes: "original"}
+	if err := server.db.InsertSubmissionRevision(revision, stale, newJudgingJob(revision.ID)); err != nil {
+		t.Fatal(err)
+	}
+	superseded, err := server.db.GetSubmission("original")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !superseded.Superseded || superseded.Verdict != "AC" || superseded.Score != 100 {
+		t.Errorf("previous revision: got %+v, want a superseded AC", superseded)
+	}
+
+	// vzporeden popravek iste oddaje ne sme razvejiti verige
+	fork := db.Submission{ID: "fork", Verdict: "PENDING", Supersedes: "original"}
+	if err := server.db.InsertSubmissionRevision(fork, stale, newJudgingJob(fork.ID)); !errors.Is(err, db.ErrAlreadySuperseded) {
+		t.Fatalf("second revision of the same submission: got %v", err)
+	}
+	if _, err := server.db.GetSubmission("fork"); err == nil {
+		t.Error("the forked revision was inserted")
+	}
+
+	// izbris zadnje revizije obnovi prejšnjo
+	if err := server.db.DeleteSubmission("revision"); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := server.db.GetSubmission("original")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Superseded {
+		t.Error("previous revision stayed superseded after the latest one was deleted")
+	}
+}
+
+func TestSubmissionResultIsGuarded(t *testing.T) {
+	server := newTestServer(t, db.Config{})
+
+	submission := db.Submission{ID: "submission", Verdict: "PENDING", Solution: "AND(A,B)"}
+	if err := server.db.InsertQueuedSubmission(submission, newJudgingJob(submission.ID)); err != nil {
+		t.Fatal(err)
+	}
+	orphaned, err := server.db.GetOrphanedSubmissions()
+	if err != nil || len(orphaned) != 0 {
+		t.Fatalf("queued submission reported as orphaned: %v %v", orphaned, err)
+	}
+
+	manual := submission
+	manual.Verdict = "MAN"
+	manual.Score = 42
+	manual.Public = true
+	if err := server.db.UpdateSubmission(manual); err != nil {
+		t.Fatal(err)
+	}
+
+	submission.Verdict = "WA"
+	if updated, err := server.db.UpdateSubmissionResult(submission); err != nil || updated {
+		t.Fatalf("result of a manually judged submission: updated=%v err=%v", updated, err)
+	}
+	stored, err := server.db.GetSubmission(submission.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Verdict != "MAN" || stored.Score != 42 || !stored.Public {
+		t.Errorf("manual verdict was overwritten: %+v", stored)
+	}
+
+	if err := server.db.InsertSubmission(db.Submission{ID: "orphan", Verdict: "PENDING"}); err != nil {
+		t.Fatal(err)
+	}
+	orphaned, err = server.db.GetOrphanedSubmissions()
+	if err != nil || len(orphaned) != 1 || orphaned[0].ID != "orphan" {
+		t.Fatalf("got orphaned %v %v, want the submission without a job", orphaned, err)
+	}
+}
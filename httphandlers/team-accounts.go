@@ -0,0 +1,159 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const generatedPasswordLength = 12
+
+var (
+	errNotContestant        = errors.New("user isn't a team account")
+	errCompetitionNotActive = errors.New("competition isn't running")
+)
+
+// TeamCredentials are returned only once, when they are set. The password isn't stored in plain text.
+type TeamCredentials struct {
+	TeamID   string
+	Username string
+	Password string
+}
+
+// contestant returns the team and the competition of a team account. Teams can only act while their competition is running.
+func (server *httpImpl) contestant(user db.User) (db.Team, db.Competition, error) {
+	if user.TeamID == "" {
+		return db.Team{}, db.Competition{}, errNotContestant
+	}
+	team, err := server.db.GetTeam(user.TeamID)
+	if err != nil {
+		return team, db.Competition{}, err
+	}
+	competition, err := server.db.GetCompetition(team.CompetitionID)
+	if err != nil {
+		return team, competition, err
+	}
+	if competition.Status != db.StatusRunning {
+		return team, competition, errCompetitionNotActive
+	}
+	return team, competition, nil
+}
+
+// writeContestantError writes the response for an error returned by contestant.
+func writeContestantError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNotContestant) {
+		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, errCompetitionNotActive) {
+		WriteJSON(w, Response{Error: "Competition isn't running"}, http.StatusForbidden)
+		return
+	}
+	WriteJSON(w, Response{Error: "Server error whilst fetching the team"}, http.StatusInternalServerError)
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, generatedPasswordLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:generatedPasswordLength], nil
+}
+
+// SetTeamCredentials creates the team's account or resets its credentials. If no password is given, a random one
+// is generated. Existing sessions of the team are logged out.
+func (server *httpImpl) SetTeamCredentials(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	team, err := server.db.GetTeam(mux.Vars(r)["team_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching a team"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, team.CompetitionID, PermManage) {
+		return
+	}
+
+	account, err := server.db.GetTeamUser(team.ID)
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		WriteJSON(w, Response{Error: "Server error whilst fetching the team account"}, http.StatusInternalServerError)
+		return
+	}
+	before := auditUser(account)
+
+	username := strings.TrimSpace(r.FormValue("username"))
+	if username == "" {
+		username = account.Username
+	}
+	if username == "" {
+		username = "team-" + strings.Split(team.ID, "-")[0]
+	}
+
+	if username != account.Username {
+		_, err := server.db.GetUserByUsername(username)
+		if err == nil {
+			WriteJSON(w, Response{Error: "Username is already taken"}, http.StatusUnprocessableEntity)
+			return
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			WriteJSON(w, Response{Error: "Server error whilst fetching the user"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	pass := r.FormValue("password")
+	if pass == "" {
+		pass, err = generatePassword()
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst generating the password"}, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	password, err := db.HashPassword(pass)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst hashing the password"}, http.StatusInternalServerError)
+		return
+	}
+
+	currentTime := int(time.Now().Unix())
+
+	account.Username = username
+	account.Password = password
+	account.LoginToken = ""
+	account.TeamID = team.ID
+	account.UpdatedAt = currentTime
+
+	if exists {
+		err = server.db.UpdateUser(account)
+	} else {
+		account.ID = uuid.NewString()
+		account.CreatedAt = currentTime
+		err = server.db.InsertUser(account)
+	}
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst saving the team account"}, http.StatusInternalServerError)
+		return
+	}
+
+	if exists {
+		server.audit(user, "update", "user", account.ID, team.CompetitionID, before, auditUser(account))
+	} else {
+		server.audit(user, "create", "user", account.ID, team.CompetitionID, nil, auditUser(account))
+	}
+
+	WriteJSON(w, Response{Data: TeamCredentials{TeamID: team.ID, Username: username, Password: pass}}, http.StatusOK)
+}
@@ -0,0 +1,185 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTeamImportSize = 1 << 20
+	maxTeamNameLength = 100
+)
+
+// TeamImportRow is the result of a single CSV row. Error is empty, if the row is valid.
+type TeamImportRow struct {
+	Row   int // line in the file
+	Name  string
+	ID    string // ID of the created team, empty in a dry run
+	Error string
+}
+
+// parseTeamCSV reads team names from the first column. A header row (name or ime) is skipped and the delimiter is
+// detected, since spreadsheets in Slovenian locales export with semicolons.
+func parseTeamCSV(data []byte) ([]TeamImportRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	firstLine, _ := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if strings.Contains(firstLine, ";") && !strings.Contains(firstLine, ",") {
+		reader.Comma = ';'
+	}
+
+	rows := make([]TeamImportRow, 0)
+	for i := 1; ; i++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSpace(record[0])
+		if i == 1 && (strings.EqualFold(name, "name") || strings.EqualFold(name, "ime")) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, TeamImportRow{Row: line, Name: name})
+	}
+	return rows, nil
+}
+
+// validateTeamRows sets the errors of empty, too long and duplicated names. Names are compared case-insensitively,
+// both within the file and against the competition's existing teams.
+func validateTeamRows(rows []TeamImportRow, existing []db.Team) bool {
+	names := make(map[string]int)
+	for _, v := range existing {
+		names[strings.ToLower(strings.TrimSpace(v.Name))] = 0
+	}
+
+	valid := true
+	for i := range rows {
+		key := strings.ToLower(rows[i].Name)
+		switch {
+		case rows[i].Name == "":
+			rows[i].Error = "Name is empty"
+		case utf8.RuneCountInString(rows[i].Name) > maxTeamNameLength:
+			rows[i].Error = fmt.Sprintf("Name is longer than %d characters", maxTeamNameLength)
+		default:
+			row, ok := names[key]
+			if ok && row == 0 {
+				rows[i].Error = "Team already exists"
+			} else if ok {
+				rows[i].Error = fmt.Sprintf("Duplicate of row %d", row)
+			} else {
+				names[key] = rows[i].Row
+			}
+		}
+		if rows[i].Error != "" {
+			valid = false
+		}
+	}
+	return valid
+}
+
+// ImportTeams creates the competition's teams from an uploaded CSV file (file), one team name per row. Teams are
+// created in a single transaction and only if every row is valid. With dry_run only the preview is returned.
+func (server *httpImpl) ImportTeams(w http.ResponseWriter, r *http.Request) {
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competition, err := server.db.GetCompetition(mux.Vars(r)["competition_id"])
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching competition"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !server.authorize(w, user, competition.ID, PermManage) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTeamImportSize)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		WriteJSON(w, Response{Error: "File is missing or too large"}, http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	dryRun := false
+	if r.FormValue("dry_run") != "" {
+		dryRun, err = strconv.ParseBool(r.FormValue("dry_run"))
+		if err != nil {
+			WriteJSON(w, Response{Error: "Dry_run is invalid. Expected a boolean."}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst reading the file"}, http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := parseTeamCSV(data)
+	if err != nil {
+		WriteJSON(w, Response{Error: "File is not a valid CSV", Data: err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if len(rows) == 0 {
+		WriteJSON(w, Response{Error: "File contains no teams"}, http.StatusBadRequest)
+		return
+	}
+
+	existing, err := server.db.GetTeamsForCompetition(competition.ID)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching teams"}, http.StatusInternalServerError)
+		return
+	}
+
+	if !validateTeamRows(rows, existing) {
+		WriteJSON(w, Response{Error: "Some rows are invalid", Data: rows}, http.StatusBadRequest)
+		return
+	}
+
+	if dryRun {
+		WriteJSON(w, Response{Data: rows}, http.StatusOK)
+		return
+	}
+
+	teams := make([]db.Team, len(rows))
+	for i := range rows {
+		rows[i].ID = uuid.NewString()
+		teams[i] = db.Team{
+			ID:            rows[i].ID,
+			Name:          rows[i].Name,
+			CompetitionID: competition.ID,
+		}
+	}
+
+	err = server.db.InsertTeams(teams)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst inserting teams"}, http.StatusInternalServerError)
+		return
+	}
+
+	for _, team := range teams {
+		server.audit(user, "create", "team", team.ID, team.CompetitionID, nil, team)
+	}
+	server.publishScoreboard(competition.ID)
+
+	WriteJSON(w, Response{Data: rows}, http.StatusCreated)
+}
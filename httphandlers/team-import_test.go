@@ -0,0 +1,72 @@
+package httphandlers
+
+import (
+	"HTTP-boilerplate/db"
+	"strings"
+	"testing"
+)
+
+func TestParseTeamCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		names []string
+		rows  []int
+	}{
+		{"header", "name\nEkipa 1\nEkipa 2\n", []string{"Ekipa 1", "Ekipa 2"}, []int{2, 3}},
+		{"slovenian header", "Ime\nEkipa\n", []string{"Ekipa"}, []int{2}},
+		{"no header", "Ekipa 1\nEkipa 2", []string{"Ekipa 1", "Ekipa 2"}, []int{1, 2}},
+		{"byte order mark", "\ufeffname\r\nŠola\r\n", []string{"Šola"}, []int{2}},
+		// slovenske nastavitve izvozijo s podpičji
+		{"semicolons", "ime;šola\nEkipa, A;Gimnazija\n", []string{"Ekipa, A"}, []int{2}},
+		{"commas", "name,school\n  Ekipa ,Gimnazija\n", []string{"Ekipa"}, []int{2}},
+		{"quoted", "\"Ekipa \"\"A\"\"\"\n", []string{`Ekipa "A"`}, []int{1}},
+		{"empty name", "name\n\"\"\nEkipa\n", []string{"", "Ekipa"}, []int{2, 3}},
+	}
+	for _, v := range tests {
+		rows, err := parseTeamCSV([]byte(v.data))
+		if err != nil {
+			t.Errorf("%s: %v", v.name, err)
+			continue
+		}
+		if len(rows) != len(v.names) {
+			t.Errorf("%s: got %+v, want %v", v.name, rows, v.names)
+			continue
+		}
+		for i := range rows {
+			if rows[i].Name != v.names[i] || rows[i].Row != v.rows[i] {
+				t.Errorf("%s: got row %+v, want %q on line %d", v.name, rows[i], v.names[i], v.rows[i])
+			}
+		}
+	}
+
+	if _, err := parseTeamCSV([]byte("\"Ekipa\n")); err == nil {
+		t.Error("unterminated quote was accepted")
+	}
+}
+
+func TestValidateTeamRows(t *testing.T) {
+	rows := []TeamImportRow{
+		{Row: 2, Name: "Ekipa 1"},
+		{Row: 3, Name: ""},
+		{Row: 4, Name: strings.Repeat("č", maxTeamNameLength+1)},
+		{Row: 5, Name: "ekipa 1"},
+		{Row: 6, Name: "OBSTOJEČA"},
+		{Row: 7, Name: strings.Repeat("č", maxTeamNameLength)},
+	}
+	existing := []db.Team{{ID: "team", Name: " Obstoječa "}}
+	if validateTeamRows(rows, existing) {
+		t.Error("invalid rows were accepted")
+	}
+	want := []string{"", "Name is empty", "Name is longer than 100 characters", "Duplicate of row 2", "Team already exists", ""}
+	for i := range rows {
+		if rows[i].Error != want[i] {
+			t.Errorf("row %d: got error %q, want %q", rows[i].Row, rows[i].Error, want[i])
+		}
+	}
+
+	rows = []TeamImportRow{{Row: 1, Name: "Ekipa 1"}, {Row: 2, Name: "Ekipa 2"}}
+	if !validateTeamRows(rows, existing) {
+		t.Errorf("valid rows were rejected: %+v", rows)
+	}
+}
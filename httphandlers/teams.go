@@ -8,14 +8,8 @@ import (
 )
 
 func (server *httpImpl) GetTeams(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -26,6 +20,10 @@ func (server *httpImpl) GetTeams(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !server.authorize(w, user, competition.ID, PermView) {
+		return
+	}
+
 	teams, err := server.db.GetTeamsForCompetition(competition.ID)
 	if err != nil {
 		WriteJSON(w, Response{Data: "Error whilst fetching problems"}, http.StatusInternalServerError)
@@ -40,14 +38,8 @@ func (server *httpImpl) GetTeams(w http.ResponseWriter, r *http.Request) {
 }
 
 func (server *httpImpl) NewTeam(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -64,6 +56,10 @@ func (server *httpImpl) NewTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !server.authorize(w, user, competition.ID, PermManage) {
+		return
+	}
+
 	id := uuid.NewString()
 
 	team := db.Team{
@@ -78,18 +74,15 @@ func (server *httpImpl) NewTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	server.audit(user, "create", "team", team.ID, team.CompetitionID, nil, team)
+	server.publishScoreboard(team.CompetitionID)
+
 	WriteJSON(w, Response{Data: id}, http.StatusCreated)
 }
 
 func (server *httpImpl) UpdateTeam(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
-		return
-	}
-
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
@@ -100,6 +93,11 @@ func (server *httpImpl) UpdateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !server.authorize(w, user, team.CompetitionID, PermManage) {
+		return
+	}
+	before := team
+
 	team.Name = r.FormValue("name")
 	if team.Name == "" {
 		WriteJSON(w, Response{Error: "Invalid name"}, http.StatusBadRequest)
@@ -112,28 +110,48 @@ func (server *httpImpl) UpdateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	server.audit(user, "update", "team", team.ID, team.CompetitionID, before, team)
+	server.publishScoreboard(team.CompetitionID)
+
 	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
 }
 
 func (server *httpImpl) DeleteTeam(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
-	if err != nil {
-		WriteForbiddenJWT(w)
+	user, ok := server.authenticate(w, r)
+	if !ok {
 		return
 	}
 
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	teamId := mux.Vars(r)["team_id"]
+	team, err := server.db.GetTeam(teamId)
+	if err != nil {
+		WriteJSON(w, Response{Error: "Server error whilst fetching a team"}, http.StatusInternalServerError)
 		return
 	}
 
-	teamId := mux.Vars(r)["team_id"]
+	if !server.authorize(w, user, team.CompetitionID, PermManage) {
+		return
+	}
 
-	err = server.db.DeleteTeam(teamId)
+	err = server.db.DeleteTeam(team.ID)
 	if err != nil {
 		WriteJSON(w, Response{Error: "Server error whilst deleting a team"}, http.StatusInternalServerError)
 		return
 	}
 
+	server.audit(user, "delete", "team", team.ID, team.CompetitionID, team, nil)
+	server.publishScoreboard(team.CompetitionID)
+
+	// račun izbrisane ekipe se ne sme več prijaviti
+	account, err := server.db.GetTeamUser(team.ID)
+	if err == nil {
+		err = server.db.DeleteUser(account.ID)
+		if err != nil {
+			WriteJSON(w, Response{Error: "Server error whilst deleting the team account"}, http.StatusInternalServerError)
+			return
+		}
+		server.audit(user, "delete", "user", account.ID, team.CompetitionID, auditUser(account), nil)
+	}
+
 	WriteJSON(w, Response{Data: "OK"}, http.StatusOK)
 }
@@ -13,6 +13,7 @@ type TokenResponse struct {
 	UserID   string `json:"user_id"`
 	IsAdmin  bool   `json:"is_admin"`
 	Username string `json:"username"`
+	TeamID   string `json:"team_id"`
 }
 
 func (server *httpImpl) Login(w http.ResponseWriter, r *http.Request) {
@@ -64,7 +65,7 @@ func (server *httpImpl) Login(w http.ResponseWriter, r *http.Request) {
 
 	http.SetCookie(w, c)
 
-	WriteJSON(w, Response{Data: TokenResponse{UserID: user.ID, Username: user.Username, IsAdmin: user.IsAdmin}, Success: true}, http.StatusOK)
+	WriteJSON(w, Response{Data: TokenResponse{UserID: user.ID, Username: user.Username, IsAdmin: user.IsAdmin, TeamID: user.TeamID}, Success: true}, http.StatusOK)
 }
 
 func (server *httpImpl) NewUser(w http.ResponseWriter, r *http.Request) {
@@ -120,6 +121,8 @@ func (server *httpImpl) NewUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	server.audit(user, "create", "user", user.ID, "", nil, auditUser(user))
+
 	WriteJSON(w, Response{Data: "Success", Success: true}, http.StatusCreated)
 }
 
@@ -150,6 +153,9 @@ func (server *httpImpl) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// gesla v dnevnik ne zapisujemo
+	server.audit(user, "change_password", "user", user.ID, "", nil, nil)
+
 	WriteJSON(w, Response{Data: "OK", Success: true}, http.StatusOK)
 }
 
@@ -7,6 +7,7 @@ import (
 	"slices"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
@@ -33,9 +34,22 @@ var upgrader = websocket.Upgrader{
 }
 
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan []byte
+	hub           *Hub
+	conn          *websocket.Conn
+	send          chan []byte
+	competitionId string
+	public        bool   // public scoreboard clients only receive scoreboard updates
+	teamId        string // team clients receive public messages and the ones meant for their team
+}
+
+func (c *Client) receives(message Message) bool {
+	if c.competitionId != message.CompetitionID {
+		return false
+	}
+	if c.teamId != "" {
+		return message.Public || message.Teams || message.TeamID == c.teamId
+	}
+	return c.public == message.Public
 }
 
 func (c *Client) readPump() {
@@ -54,8 +68,12 @@ func (c *Client) readPump() {
 			}
 			break
 		}
+		// gledalci javne lestvice in ekipe ne smejo pošiljati sporočil drugim
+		if c.public || c.teamId != "" {
+			continue
+		}
 		message = bytes.TrimSpace(bytes.Replace(message, newline, space, -1))
-		c.hub.broadcast <- message
+		c.hub.broadcast <- Message{CompetitionID: c.competitionId, Data: message}
 	}
 }
 
@@ -99,17 +117,35 @@ func (c *Client) writePump() {
 }
 
 func (server *httpImpl) UpgradeConnection(w http.ResponseWriter, r *http.Request) {
-	user, err := server.db.CheckToken(GetToken(r))
+	user, ok := server.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	competitionId := mux.Vars(r)["competition_id"]
+	role, err := server.competitionRole(user, competitionId)
 	if err != nil {
-		WriteForbiddenJWT(w)
+		WriteJSON(w, Response{Error: "Server error whilst checking permissions"}, http.StatusInternalServerError)
+		return
+	}
+
+	// ekipe dobijo le javna sporočila in rezultate svojih oddaj
+	if role == RoleTeam {
+		server.serveWebsocket(w, r, competitionId, false, user.TeamID)
 		return
 	}
 
-	if !user.IsAdmin {
-		WriteJSON(w, Response{Error: "Forbidden"}, http.StatusForbidden)
+	// websocket prenaša tudi neobjavljene rezultate in zasebna vprašanja
+	if !server.authorize(w, user, competitionId, PermJudge) {
 		return
 	}
 
+	server.serveWebsocket(w, r, competitionId, false, "")
+}
+
+// serveWebsocket upgrades the connection and registers the client for the competition's messages. Team clients
+// are given the team's ID.
+func (server *httpImpl) serveWebsocket(w http.ResponseWriter, r *http.Request, competitionId string, public bool, teamId string) {
 	upgrader.CheckOrigin = func(r *http.Request) bool {
 		origin := r.Header.Get("Origin")
 		if server.config.Debug {
@@ -122,7 +158,7 @@ func (server *httpImpl) UpgradeConnection(w http.ResponseWriter, r *http.Request
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: server.hub, conn: conn, send: make(chan []byte, 256)}
+	client := &Client{hub: server.hub, conn: conn, send: make(chan []byte, 256), competitionId: competitionId, public: public, teamId: teamId}
 	client.hub.register <- client
 
 	go client.writePump()
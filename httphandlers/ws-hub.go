@@ -2,18 +2,29 @@ package httphandlers
 
 import "fmt"
 
+// Message is delivered only to the clients of its competition. Public messages are sent to the public scoreboard
+// feed and to the teams, the rest to the authenticated clients. If Teams is set, all teams receive the message too,
+// if TeamID is set, only that team does.
+type Message struct {
+	CompetitionID string
+	Public        bool
+	Teams         bool
+	TeamID        string
+	Data          []byte
+}
+
 // Hub maintains the set of active clients and broadcasts messages to the
 // clients.
 type Hub struct {
 	clients    map[*Client]bool
-	broadcast  chan []byte
+	broadcast  chan Message
 	register   chan *Client
 	unregister chan *Client
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		broadcast:  make(chan []byte),
+		broadcast:  make(chan Message),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
@@ -33,8 +44,11 @@ func (h *Hub) Run() {
 			}
 		case message := <-h.broadcast:
 			for client := range h.clients {
+				if !client.receives(message) {
+					continue
+				}
 				select {
-				case client.send <- message:
+				case client.send <- message.Data:
 				default:
 					close(client.send)
 					delete(h.clients, client)
@@ -30,3 +30,85 @@ type WSChangeSubmissionID struct {
 	OldSubmission string `json:"old_submission"`
 	NewSubmission string `json:"new_submission"`
 }
+
+// State je QUEUED, JUDGING, JUDGED ali FAILED. Verdict in točke so poslani samo, ko je ocenjevanje končano.
+type WSJudgingState struct {
+	MessageType int    `json:"message_type"`
+	Submission  string `json:"submission"`
+	TeamID      string `json:"team_id"`
+	ProblemID   string `json:"problem_id"`
+	State       string `json:"state"`
+	Verdict     string `json:"verdict"`
+	Score       int    `json:"score"`
+}
+
+type WSNewClarification struct {
+	MessageType   int    `json:"message_type"`
+	Clarification string `json:"clarification"`
+	CompetitionID string `json:"competition_id"`
+	TeamID        string `json:"team_id"`
+	TeamName      string `json:"team_name"`
+	ProblemID     string `json:"problem_id"`
+	Question      string `json:"question"`
+}
+
+// Public je true, če je odgovor namenjen vsem ekipam
+type WSClarificationAnswer struct {
+	MessageType   int    `json:"message_type"`
+	Clarification string `json:"clarification"`
+	CompetitionID string `json:"competition_id"`
+	TeamID        string `json:"team_id"`
+	ProblemID     string `json:"problem_id"`
+	Question      string `json:"question"`
+	Answer        string `json:"answer"`
+	Public        bool   `json:"public"`
+}
+
+// Scoreboard je sestavljen enako kot pri GET /public/competition/{competition_id}/scoreboard
+type WSScoreboard struct {
+	MessageType   int         `json:"message_type"`
+	CompetitionID string      `json:"competition_id"`
+	Scoreboard    Leaderboard `json:"scoreboard"`
+}
+
+// Remaining je število rezultatov, ki so še skriti
+type WSReveal struct {
+	MessageType   int    `json:"message_type"`
+	CompetitionID string `json:"competition_id"`
+	TeamID        string `json:"team_id"`
+	TeamName      string `json:"team_name"`
+	ProblemID     string `json:"problem_id"`
+	Verdict       string `json:"verdict"`
+	Score         int    `json:"score"`
+	TotalScore    int    `json:"total_score"`
+	Remaining     int    `json:"remaining"`
+}
+
+// Status je eden izmed db.Status*, ContestTime je pretečen čas tekmovanja v sekundah brez premorov
+type WSCompetitionState struct {
+	MessageType    int    `json:"message_type"`
+	CompetitionID  string `json:"competition_id"`
+	Status         int    `json:"status"`
+	StatusName     string `json:"status_name"`
+	PreviousStatus int    `json:"previous_status"`
+	StartTime      int    `json:"start_time"`
+	EndTime        int    `json:"end_time"`
+	PlannedEnd     int    `json:"planned_end"`
+	Duration       int    `json:"duration"`
+	ContestTime    int    `json:"contest_time"`
+}
+
+// Clock vsebuje tudi čas strežnika, da lahko klient izračuna zamik svoje ure
+type WSClock struct {
+	MessageType int `json:"message_type"`
+	Clock
+}
+
+type WSAnnouncement struct {
+	MessageType   int    `json:"message_type"`
+	Announcement  string `json:"announcement"`
+	CompetitionID string `json:"competition_id"`
+	ProblemID     string `json:"problem_id"`
+	Text          string `json:"text"`
+	CreatedAt     int    `json:"created_at"`
+}
@@ -0,0 +1,203 @@
+package judge
+
+import (
+	"HTTP-boilerplate/ast"
+	"HTTP-boilerplate/scoring"
+	"errors"
+	"fmt"
+)
+
+// Task describes everything needed to judge a submission for a problem.
+type Task struct {
+	Solutions        []string // reference solutions, the first one is the primary solution used for testing
+	IsBaseLogicOnly  bool
+	SampleSize       int
+	MandatoryVectors []string
+	Weights          []ast.RowWeight
+	Seed             int64
+}
+
+type Evaluation struct {
+	Verdict          string
+	EvaluationLog    string
+	CorrectTestCases int
+	WrongTestCases   int
+
+	Reference  int  // index of the closest reference solution
+	Identical  bool // whether the submission is identical (hash-wise) to the closest reference solution
+	Optimal    bool // whether the submission is identical or not longer than the closest reference solution
+	Sampled    bool
+	SampleSize int
+	Seed       int64
+	Confidence float64
+
+	CorrectWeight   float64
+	TotalWeight     float64
+	FailedMandatory []string
+}
+
+// ScoringInput converts the evaluation into the input of a scoring policy.
+func (evaluation Evaluation) ScoringInput(points int, previousSubmissions int, submittedAfter int) scoring.Input {
+	return scoring.Input{
+		Verdict:             evaluation.Verdict,
+		Points:              points,
+		CorrectTestCases:    evaluation.CorrectTestCases,
+		WrongTestCases:      evaluation.WrongTestCases,
+		CorrectWeight:       evaluation.CorrectWeight,
+		TotalWeight:         evaluation.TotalWeight,
+		MandatoryFailed:     len(evaluation.FailedMandatory),
+		PreviousSubmissions: previousSubmissions,
+		SubmittedAfter:      submittedAfter,
+	}
+}
+
+type reference struct {
+	solution string
+	hash     int
+	length   int
+}
+
+// closestReference returns the index of the reference, which is identical to the submission, or the shortest one,
+// if none of them are identical. References are equally minimal, but a correct submission must never be judged
+// against a longer one.
+func closestReference(references []reference, hash int) int {
+	closest := 0
+	for i, v := range references {
+		if v.hash == hash {
+			return i
+		}
+		if v.length < references[closest].length {
+			closest = i
+		}
+	}
+	return closest
+}
+
+// Evaluate compiles the submission and tests it against the primary reference solution. If the submission is correct,
+// it is compared to the identical or else the shortest reference solution, to decide between the AC and PART verdicts.
+func Evaluate(task Task, submission string) (*Evaluation, error) {
+	if len(task.Solutions) == 0 {
+		return nil, errors.New("task has no reference solutions")
+	}
+
+	evaluation := Evaluation{}
+
+	sub, err := ast.BuildAST(submission)
+	if err != nil {
+		evaluation.EvaluationLog = err.Error()
+		evaluation.Verdict = "CF" // Compilation failure
+		return &evaluation, nil
+	}
+
+	if task.IsBaseLogicOnly && !ast.VerifyAgainstBase(sub) {
+		evaluation.EvaluationLog = "AST could not verify the submission's logic against only base logic gates (AND, OR, NOT)"
+		evaluation.Verdict = "CF" // Compilation failure
+		return &evaluation, nil
+	}
+
+	references := make([]reference, 0)
+	var sol *ast.AST
+	for i, v := range task.Solutions {
+		a, err := ast.BuildAST(v)
+		if err != nil {
+			evaluation.EvaluationLog = err.Error()
+			evaluation.Verdict = "SOL_CF" // Solution compilation failure
+			return &evaluation, nil
+		}
+		if task.IsBaseLogicOnly && !ast.VerifyAgainstBase(a) {
+			evaluation.EvaluationLog = "AST could not verify the SOLUTION's logic against only base logic gates (AND, OR, NOT)"
+			evaluation.Verdict = "SOL_CF" // Solution compilation failure
+			return &evaluation, nil
+		}
+		if i == 0 {
+			sol = a
+		}
+		references = append(references, reference{solution: v, hash: ast.HashAST(a), length: ast.ASTLength(a, 0)})
+	}
+
+	test, err := ast.TestDigitalSolutionWithOptions(sub, sol, ast.TestOptions{
+		SampleSize:       task.SampleSize,
+		Seed:             task.Seed,
+		MandatoryVectors: task.MandatoryVectors,
+		Weights:          task.Weights,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation.Verdict = test.Verdict
+	evaluation.EvaluationLog = test.EvaluationLog
+	evaluation.CorrectTestCases = test.CorrectTestCases
+	evaluation.WrongTestCases = test.WrongTestCases
+	evaluation.Sampled = test.Sampled
+	evaluation.SampleSize = test.SampleSize
+	evaluation.Seed = test.Seed
+	evaluation.Confidence = test.Confidence
+	evaluation.CorrectWeight = test.CorrectWeight
+	evaluation.TotalWeight = test.TotalWeight
+	evaluation.FailedMandatory = test.FailedMandatory
+
+	if evaluation.Verdict != "AC" {
+		return &evaluation, nil
+	}
+
+	subH := ast.HashAST(sub)
+	subL := ast.ASTLength(sub, 0)
+	evaluation.Reference = closestReference(references, subH)
+	ref := references[evaluation.Reference]
+	evaluation.Identical = ref.hash == subH
+	evaluation.Optimal = evaluation.Identical || subL <= ref.length
+
+	if len(references) > 1 {
+		evaluation.EvaluationLog += fmt.Sprintf("Compared with reference solution #%d out of %d: %s.\n", evaluation.Reference+1, len(references), ref.solution)
+	}
+
+	if evaluation.Optimal {
+		evaluation.EvaluationLog += fmt.Sprintf("Equality check passed! Contestant: %s (%d, len: %d), Judge: %s (%d, len: %d).\n", submission, subH, subL, ref.solution, ref.hash, ref.length)
+	} else {
+		evaluation.EvaluationLog += fmt.Sprintf("Applying PART verdict! Contestant: %s (%d, len: %d), Judge: %s (%d, len: %d).\n", submission, subH, subL, ref.solution, ref.hash, ref.length)
+		evaluation.Verdict = "PART"
+	}
+
+	return &evaluation, nil
+}
+
+// VerifyReferences checks that all reference solutions compile, are equivalent to the primary one and aren't longer
+// than it. Equivalence is checked on the whole truth table for up to ast.MaxEquivalenceInputs inputs, wider problems
+// are compared on the task's seeded sample.
+func VerifyReferences(task Task) error {
+	if len(task.Solutions) == 0 {
+		return errors.New("task has no reference solutions")
+	}
+	sol, err := ast.BuildAST(task.Solutions[0])
+	if err != nil {
+		return err
+	}
+	if task.IsBaseLogicOnly && !ast.VerifyAgainstBase(sol) {
+		return errors.New("AST verification of the primary solution against base components failed")
+	}
+	for i, v := range task.Solutions[1:] {
+		a, err := ast.BuildAST(v)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Reference solution #%d: %s", i+2, err.Error()))
+		}
+		if task.IsBaseLogicOnly && !ast.VerifyAgainstBase(a) {
+			return errors.New(fmt.Sprintf("Reference solution #%d: AST verification against base components failed", i+2))
+		}
+		equivalent, err := ast.Equivalent(a, sol, ast.TestOptions{
+			SampleSize:       task.SampleSize,
+			Seed:             task.Seed,
+			MandatoryVectors: task.MandatoryVectors,
+		})
+		if err != nil {
+			return err
+		}
+		if !equivalent {
+			return errors.New(fmt.Sprintf("Reference solution #%d (%s) is not equivalent to the primary solution", i+2, v))
+		}
+		if ast.ASTLength(a, 0) > ast.ASTLength(sol, 0) {
+			return errors.New(fmt.Sprintf("Reference solution #%d (%s) is longer than the primary solution, reference solutions have to be equally minimal", i+2, v))
+		}
+	}
+	return nil
+}
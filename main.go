@@ -1,6 +1,7 @@
 package main
 
 import (
+	"HTTP-boilerplate/blobstore"
 	"HTTP-boilerplate/db"
 	"HTTP-boilerplate/httphandlers"
 	"fmt"
@@ -51,10 +52,29 @@ func main() {
 		return
 	}
 
+	// starejše nastavitve mape za priponke še nimajo
+	if config.BlobDirectory == "" {
+		config.BlobDirectory = "blobs"
+	}
+	blobs, err := blobstore.NewLocalStore(config.BlobDirectory)
+	if err != nil {
+		sugared.Fatal("Error while creating the blob store: ", err.Error())
+		return
+	}
+
+	// ukaza export in import prenašata tekmovanja med strežniki, namesto da bi zagnala strežnik
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(database, blobs, os.Args[1:]))
+	}
+
 	hub := httphandlers.NewHub()
 	go hub.Run()
 
-	httphandler := httphandlers.NewHTTPInterface(sugared, database, config, hub)
+	queue := httphandlers.NewJudgeQueue(config.JudgeWorkers)
+
+	httphandler := httphandlers.NewHTTPInterface(sugared, database, config, hub, queue, blobs)
+	go httphandler.RunJudgeQueue()
+	go httphandler.RunScheduler()
 
 	sugared.Info("Database created successfully")
 
@@ -68,24 +88,62 @@ func main() {
 	r.HandleFunc("/problem/{problem_id}/submission", httphandler.NewSubmission).Methods("POST")
 	r.HandleFunc("/submission/{submission_id}", httphandler.UpdateSubmission).Methods("PATCH")
 	r.HandleFunc("/submission/{submission_id}", httphandler.DeleteSubmission).Methods("DELETE")
+	r.HandleFunc("/submission/{submission_id}/revisions", httphandler.GetSubmissionRevisions).Methods("GET")
 
 	r.HandleFunc("/competitions", httphandler.GetCompetitions).Methods("GET")
 	r.HandleFunc("/competitions", httphandler.NewCompetition).Methods("POST")
+	r.HandleFunc("/competitions/import", httphandler.ImportCompetition).Methods("POST")
 	r.HandleFunc("/competition/{competition_id}", httphandler.UpdateCompetition).Methods("PATCH")
 	r.HandleFunc("/competition/{competition_id}", httphandler.DeleteCompetition).Methods("DELETE")
 	r.HandleFunc("/competition/{competition_id}", httphandler.BuildCompetitionLeaderboard).Methods("GET")
+	r.HandleFunc("/competition/{competition_id}/clone", httphandler.CloneCompetition).Methods("POST")
+	r.HandleFunc("/competition/{competition_id}/export", httphandler.ExportCompetition).Methods("GET")
+	r.HandleFunc("/competition/{competition_id}/export/standings", httphandler.ExportStandings).Methods("GET")
+	r.HandleFunc("/competition/{competition_id}/export/submissions", httphandler.ExportSubmissions).Methods("GET")
 
 	r.HandleFunc("/competition/{competition_id}/websocket", httphandler.UpgradeConnection).Methods("GET")
 
+	r.HandleFunc("/public/competition/{competition_id}/scoreboard", httphandler.GetPublicScoreboard).Methods("GET")
+	r.HandleFunc("/public/competition/{competition_id}/websocket", httphandler.PublicScoreboardConnection).Methods("GET")
+	r.HandleFunc("/competition/{competition_id}/reveal", httphandler.RevealNext).Methods("POST")
+	r.HandleFunc("/competition/{competition_id}/clock", httphandler.GetClock).Methods("GET")
+	r.HandleFunc("/public/competition/{competition_id}/clock", httphandler.GetPublicClock).Methods("GET")
+
 	r.HandleFunc("/competition/{competition_id}/problems", httphandler.GetProblems).Methods("GET")
 	r.HandleFunc("/competition/{competition_id}/problems", httphandler.NewProblem).Methods("POST")
 	r.HandleFunc("/problem/{problem_id}", httphandler.UpdateProblem).Methods("PATCH")
 	r.HandleFunc("/problem/{problem_id}", httphandler.DeleteProblem).Methods("DELETE")
 
+	r.HandleFunc("/problem/{problem_id}/attachments", httphandler.GetAttachments).Methods("GET")
+	r.HandleFunc("/problem/{problem_id}/attachments", httphandler.UploadAttachment).Methods("POST")
+	r.HandleFunc("/attachment/{attachment_id}", httphandler.DownloadAttachment).Methods("GET")
+	r.HandleFunc("/attachment/{attachment_id}", httphandler.DeleteAttachment).Methods("DELETE")
+
+	r.HandleFunc("/problem/{problem_id}/rejudge", httphandler.RejudgeProblem).Methods("POST")
+	r.HandleFunc("/competition/{competition_id}/rejudge", httphandler.RejudgeCompetition).Methods("POST")
+	r.HandleFunc("/rejudge/{rejudge_id}", httphandler.GetRejudge).Methods("GET")
+
+	r.HandleFunc("/competition/{competition_id}/clarifications", httphandler.GetClarifications).Methods("GET")
+	r.HandleFunc("/competition/{competition_id}/clarifications", httphandler.NewClarification).Methods("POST")
+	r.HandleFunc("/clarification/{clarification_id}", httphandler.AnswerClarification).Methods("PATCH")
+	r.HandleFunc("/clarification/{clarification_id}", httphandler.DeleteClarification).Methods("DELETE")
+	r.HandleFunc("/competition/{competition_id}/announcements", httphandler.GetAnnouncements).Methods("GET")
+	r.HandleFunc("/competition/{competition_id}/announcements", httphandler.NewAnnouncement).Methods("POST")
+	r.HandleFunc("/announcement/{announcement_id}", httphandler.DeleteAnnouncement).Methods("DELETE")
+
+	r.HandleFunc("/audit", httphandler.GetAuditLog).Methods("GET")
+
 	r.HandleFunc("/competition/{competition_id}/teams", httphandler.GetTeams).Methods("GET")
 	r.HandleFunc("/competition/{competition_id}/teams", httphandler.NewTeam).Methods("POST")
+	r.HandleFunc("/competition/{competition_id}/teams/import", httphandler.ImportTeams).Methods("POST")
 	r.HandleFunc("/team/{team_id}", httphandler.UpdateTeam).Methods("PATCH")
 	r.HandleFunc("/team/{team_id}", httphandler.DeleteTeam).Methods("DELETE")
+	r.HandleFunc("/team/{team_id}/credentials", httphandler.SetTeamCredentials).Methods("POST")
+	r.HandleFunc("/team/{team_id}/submissions", httphandler.GetTeamSubmissions).Methods("GET")
+
+	r.HandleFunc("/competition/{competition_id}/members", httphandler.GetMembers).Methods("GET")
+	r.HandleFunc("/competition/{competition_id}/members", httphandler.SetMember).Methods("POST")
+	r.HandleFunc("/competition/{competition_id}/members/{user_id}", httphandler.DeleteMember).Methods("DELETE")
 
 	o := cors.Options{
 		AllowedMethods:   []string{"POST", "GET", "DELETE", "PATCH", "PUT"},
@@ -0,0 +1 @@
+ALTER TABLE problems ADD COLUMN alternative_solutions VARCHAR(5000) DEFAULT '';
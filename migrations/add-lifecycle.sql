@@ -0,0 +1,6 @@
+ALTER TABLE competitions ADD COLUMN planned_start INTEGER DEFAULT 0;
+ALTER TABLE competitions ADD COLUMN planned_end INTEGER DEFAULT 0;
+ALTER TABLE competitions ADD COLUMN duration INTEGER DEFAULT 0;
+ALTER TABLE competitions ADD COLUMN end_time INTEGER DEFAULT 0;
+ALTER TABLE competitions ADD COLUMN paused_at INTEGER DEFAULT 0;
+ALTER TABLE competitions ADD COLUMN paused_time INTEGER DEFAULT 0;
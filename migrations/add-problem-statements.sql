@@ -0,0 +1,3 @@
+ALTER TABLE problems ADD COLUMN statement TEXT DEFAULT '';
+ALTER TABLE problems ADD COLUMN input_description TEXT DEFAULT '';
+ALTER TABLE problems ADD COLUMN output_description TEXT DEFAULT '';
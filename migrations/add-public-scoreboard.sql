@@ -0,0 +1 @@
+ALTER TABLE competitions ADD COLUMN public_scoreboard BOOLEAN DEFAULT false;
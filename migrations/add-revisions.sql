@@ -0,0 +1,3 @@
+ALTER TABLE submissions ADD COLUMN supersedes VARCHAR(40) DEFAULT '';
+ALTER TABLE submissions ADD COLUMN superseded BOOLEAN DEFAULT false;
+ALTER TABLE submissions ADD COLUMN created_by VARCHAR(40) DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE problems ADD COLUMN row_weights VARCHAR(5000) DEFAULT '';
@@ -0,0 +1,4 @@
+ALTER TABLE problems ADD COLUMN sample_size INTEGER DEFAULT 0;
+ALTER TABLE problems ADD COLUMN mandatory_vectors VARCHAR(5000) DEFAULT '';
+ALTER TABLE submissions ADD COLUMN seed BIGINT DEFAULT 0;
+ALTER TABLE submissions ADD COLUMN sample_size INTEGER DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE competitions ADD COLUMN freeze_time INTEGER DEFAULT 0;
+ALTER TABLE submissions ADD COLUMN revealed BOOLEAN DEFAULT false;
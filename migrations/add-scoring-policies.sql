@@ -0,0 +1,5 @@
+ALTER TABLE competitions ADD COLUMN scoring_policy VARCHAR(40) DEFAULT 'default';
+ALTER TABLE competitions ADD COLUMN scoring_parameters VARCHAR(2000) DEFAULT '';
+ALTER TABLE submissions ADD COLUMN penalty_time INTEGER DEFAULT 0;
+ALTER TABLE submissions ADD COLUMN scoring_policy VARCHAR(40) DEFAULT '';
+ALTER TABLE submissions ADD COLUMN scoring_parameters VARCHAR(2000) DEFAULT '';
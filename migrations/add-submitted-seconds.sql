@@ -0,0 +1,3 @@
+ALTER TABLE submissions ADD COLUMN submitted_seconds INTEGER DEFAULT 0;
+ALTER TABLE submissions ADD COLUMN time_override VARCHAR(500) DEFAULT '';
+UPDATE submissions SET submitted_seconds = submitted_after * 60;
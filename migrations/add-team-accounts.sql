@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN team_id VARCHAR(40) DEFAULT '';
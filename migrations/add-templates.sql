@@ -0,0 +1 @@
+ALTER TABLE competitions ADD COLUMN is_template BOOLEAN DEFAULT false;
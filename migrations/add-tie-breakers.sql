@@ -0,0 +1 @@
+ALTER TABLE competitions ADD COLUMN tie_breakers VARCHAR(250) DEFAULT '';
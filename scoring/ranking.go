@@ -0,0 +1,86 @@
+package scoring
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Tie-breakers decide the order of teams with the same score. For all of them, less is better.
+const (
+	TieLastImprovement = "last_improvement" // contest time of the last submission, which raised the team's score
+	TiePenalty         = "penalty"          // sum of penalty minutes, given by the scoring policy
+	TieAttempts        = "attempts"         // number of submissions
+)
+
+var DefaultTieBreakers = []string{TiePenalty, TieLastImprovement, TieAttempts}
+
+var tieBreakers = []string{TieLastImprovement, TiePenalty, TieAttempts}
+
+// Standing is the team's result, as seen by the ranking.
+type Standing struct {
+	Score           int
+	PenaltyTime     int
+	LastImprovement int
+	Attempts        int
+}
+
+// ParseTieBreakers parses a comma separated list of tie-breakers. An empty list returns the default tie-breakers.
+func ParseTieBreakers(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultTieBreakers, nil
+	}
+	result := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if !slices.Contains(tieBreakers, v) {
+			return nil, errors.New(fmt.Sprintf("unknown tie-breaker %s", v))
+		}
+		if slices.Contains(result, v) {
+			return nil, errors.New(fmt.Sprintf("tie-breaker %s is given more than once", v))
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (standing Standing) tieBreaker(name string) int {
+	switch name {
+	case TieLastImprovement:
+		return standing.LastImprovement
+	case TiePenalty:
+		return standing.PenaltyTime
+	case TieAttempts:
+		return standing.Attempts
+	}
+	return 0
+}
+
+// Compare returns a negative number, if a is ranked before b, a positive number, if b is ranked before a, and 0 if
+// they are tied.
+func Compare(a Standing, b Standing, tieBreakers []string) int {
+	if a.Score != b.Score {
+		return b.Score - a.Score
+	}
+	for _, v := range tieBreakers {
+		if d := a.tieBreaker(v) - b.tieBreaker(v); d != 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Ranks returns the ranks of already sorted standings. Tied standings share the place and the following places are
+// skipped (1, 2, 2, 4).
+func Ranks(standings []Standing, tieBreakers []string) []int {
+	ranks := make([]int, len(standings))
+	for i := range standings {
+		if i > 0 && Compare(standings[i-1], standings[i], tieBreakers) == 0 {
+			ranks[i] = ranks[i-1]
+			continue
+		}
+		ranks[i] = i + 1
+	}
+	return ranks
+}
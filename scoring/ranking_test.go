@@ -0,0 +1,65 @@
+package scoring
+
+import (
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	better := Standing{Score: 300, PenaltyTime: 50}
+	worse := Standing{Score: 200}
+	if Compare(better, worse, DefaultTieBreakers) >= 0 || Compare(worse, better, DefaultTieBreakers) <= 0 {
+		t.Error("higher score isn't ranked first")
+	}
+
+	a := Standing{Score: 100, PenaltyTime: 10, LastImprovement: 500, Attempts: 3}
+	b := Standing{Score: 100, PenaltyTime: 20, LastImprovement: 100, Attempts: 1}
+	if Compare(a, b, []string{TiePenalty}) >= 0 {
+		t.Error("lower penalty isn't ranked first")
+	}
+	if Compare(a, b, []string{TieLastImprovement, TiePenalty}) <= 0 {
+		t.Error("earlier last improvement isn't ranked first")
+	}
+	if Compare(a, b, []string{TieAttempts}) <= 0 {
+		t.Error("fewer attempts aren't ranked first")
+	}
+	if Compare(a, b, []string{}) != 0 {
+		t.Error("equal scores without tie-breakers aren't tied")
+	}
+}
+
+func TestRanks(t *testing.T) {
+	standings := []Standing{
+		{Score: 100, PenaltyTime: 30},
+		{Score: 300, PenaltyTime: 10},
+		{Score: 200, PenaltyTime: 20},
+		{Score: 200, PenaltyTime: 20},
+		{Score: 200, PenaltyTime: 25},
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return Compare(standings[i], standings[j], DefaultTieBreakers) < 0
+	})
+	if got, want := Ranks(standings, DefaultTieBreakers), []int{1, 2, 2, 4, 5}; !slices.Equal(got, want) {
+		t.Errorf("got ranks %v, want %v", got, want)
+	}
+	// brez razločevanja je izenačenih vseh enakih rezultatov
+	if got, want := Ranks(standings, []string{}), []int{1, 2, 2, 2, 5}; !slices.Equal(got, want) {
+		t.Errorf("without tie-breakers: got ranks %v, want %v", got, want)
+	}
+}
+
+func TestParseTieBreakers(t *testing.T) {
+	got, err := ParseTieBreakers(" attempts , penalty")
+	if err != nil || !slices.Equal(got, []string{TieAttempts, TiePenalty}) {
+		t.Errorf("got %v %v", got, err)
+	}
+	if got, err := ParseTieBreakers(""); err != nil || !slices.Equal(got, DefaultTieBreakers) {
+		t.Errorf("empty list: got %v %v, want the defaults", got, err)
+	}
+	for _, invalid := range []string{"score", "penalty,penalty"} {
+		if _, err := ParseTieBreakers(invalid); err == nil {
+			t.Errorf("%s was accepted", invalid)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package scoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Parameters holds every constant used by the scoring policies. They are configurable per competition.
+type Parameters struct {
+	PartRatio           float64 `json:"part_ratio"`           // share of points for a correct, but non-optimal (PART) submission
+	WrongAnswerRatio    float64 `json:"wrong_answer_ratio"`   // share of points, multiplied by the correct test case ratio, for a WA submission
+	ResubmissionPenalty int     `json:"resubmission_penalty"` // points subtracted for each earlier submission
+	TimePenalty         int     `json:"time_penalty"`         // points subtracted for each minute
+	TimePenaltyEach     int     `json:"time_penalty_each"`    // competition's penalty_each, the default policy keeps the original per-minute time penalty and ignores it
+	AttemptPenaltyTime  int     `json:"attempt_penalty_time"` // penalty minutes for each rejected attempt (ICPC)
+}
+
+func DefaultParameters() Parameters {
+	return Parameters{
+		PartRatio:           0.9,
+		WrongAnswerRatio:    0.63,
+		ResubmissionPenalty: 30,
+		TimePenalty:         0,
+		TimePenaltyEach:     1,
+		AttemptPenaltyTime:  20,
+	}
+}
+
+// ParseParameters parses (possibly partial) JSON parameters on top of the default ones.
+func ParseParameters(s string) (Parameters, error) {
+	parameters := DefaultParameters()
+	if s == "" {
+		return parameters, nil
+	}
+	err := json.Unmarshal([]byte(s), &parameters)
+	if err != nil {
+		return parameters, err
+	}
+	return parameters, parameters.Validate()
+}
+
+func (parameters Parameters) Validate() error {
+	if parameters.PartRatio < 0 || parameters.PartRatio > 1 {
+		return errors.New("part_ratio must be on interval [0, 1]")
+	}
+	if parameters.WrongAnswerRatio < 0 || parameters.WrongAnswerRatio > 1 {
+		return errors.New("wrong_answer_ratio must be on interval [0, 1]")
+	}
+	if parameters.ResubmissionPenalty < 0 || parameters.TimePenalty < 0 || parameters.AttemptPenaltyTime < 0 {
+		return errors.New("penalties must be non-negative")
+	}
+	if parameters.TimePenaltyEach <= 0 {
+		return errors.New("time_penalty_each must be positive")
+	}
+	return nil
+}
+
+func (parameters Parameters) String() string {
+	marshal, _ := json.Marshal(parameters)
+	return string(marshal)
+}
+
+// Input is the judged submission, as seen by the scorer.
+type Input struct {
+	Verdict             string
+	Points              int // maximum points of the problem
+	CorrectTestCases    int
+	WrongTestCases      int
+	CorrectWeight       float64 // weighted correct test cases, ignored if TotalWeight is 0
+	TotalWeight         float64
+	MandatoryFailed     int // number of wrongly answered mandatory rows
+	PreviousSubmissions int
+	SubmittedAfter      int // minutes
+}
+
+type Result struct {
+	Score       int
+	PenaltyTime int // penalty minutes, used by time-based rankings
+	Log         string
+}
+
+type Scorer interface {
+	Name() string
+	Score(input Input, parameters Parameters) Result
+}
+
+// Attempt is a scored submission of a team for a single problem, as seen by the leaderboard.
+type Attempt struct {
+	Verdict        string
+	Score          int
+	PenaltyTime    int
+	SubmittedAfter int // minutes
+}
+
+// Aggregator is implemented by the policies, which don't count the latest attempt of a problem on leaderboards.
+type Aggregator interface {
+	// Aggregate returns the index of the counted attempt along with the problem's penalty time. Attempts are
+	// ordered by submission time.
+	Aggregate(attempts []Attempt, parameters Parameters) (int, int)
+}
+
+// Aggregate picks the attempt, which counts towards the leaderboard. Unless the policy is an Aggregator, this is
+// the latest attempt.
+func Aggregate(scorer Scorer, attempts []Attempt, parameters Parameters) (int, int) {
+	if aggregator, ok := scorer.(Aggregator); ok {
+		return aggregator.Aggregate(attempts, parameters)
+	}
+	last := len(attempts) - 1
+	return last, attempts[last].PenaltyTime
+}
+
+const DefaultPolicy = "default"
+
+var policies = map[string]Scorer{
+	DefaultPolicy:    defaultScorer{},
+	"icpc":           icpcScorer{},
+	"percentage":     percentageScorer{},
+	"all_or_nothing": allOrNothingScorer{},
+}
+
+// Get returns the scoring policy with the given name. An empty name returns the default policy.
+func Get(name string) (Scorer, error) {
+	if name == "" {
+		name = DefaultPolicy
+	}
+	scorer, ok := policies[name]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown scoring policy %s", name))
+	}
+	return scorer, nil
+}
+
+func Policies() []string {
+	names := make([]string, 0)
+	for i := range policies {
+		names = append(names, i)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// correctRatio returns the (weighted) ratio of correct test cases. A failed mandatory row zeroes the ratio.
+func correctRatio(input Input) float64 {
+	if input.MandatoryFailed > 0 {
+		return 0
+	}
+	if input.TotalWeight > 0 {
+		return input.CorrectWeight / input.TotalWeight
+	}
+	if input.CorrectTestCases+input.WrongTestCases == 0 {
+		return 0
+	}
+	return float64(input.CorrectTestCases) / float64(input.WrongTestCases+input.CorrectTestCases)
+}
+
+// weightLog explains how weights affected the partial score.
+func weightLog(input Input) string {
+	if input.MandatoryFailed > 0 {
+		return fmt.Sprintf("%d mandatory rows were answered wrongly, partial score is zeroed.\n", input.MandatoryFailed)
+	}
+	if input.TotalWeight > 0 {
+		return fmt.Sprintf("Partial score uses weighted correctness %g/%g (%.2f %%).\n", input.CorrectWeight, input.TotalWeight, correctRatio(input)*100)
+	}
+	return ""
+}
+
+// defaultScorer is the original scoring of the competition.
+type defaultScorer struct{}
+
+func (defaultScorer) Name() string {
+	return DefaultPolicy
+}
+
+func (defaultScorer) Score(input Input, parameters Parameters) Result {
+	result := Result{}
+	points := 0
+	if input.Verdict == "AC" {
+		points = input.Points
+	} else if input.Verdict == "PART" {
+		points = int(float64(input.Points) * parameters.PartRatio)
+	} else if input.Verdict == "WA" {
+		// 0.63 izhaja iz tega, da so točke deljene tako:
+		// 1. del (90 % vseh točk):
+		//    - 70 % 1. dela (skupaj 63 %) gre testnim primerom
+		//    - preostalih 30 % točk prvega dela (skupaj 27 %) gre ekvivalenci vsem testnim primerom, ki v takem primeru ni zadoščena
+		// 2. del (10 % vseh točk):
+		//    - vseh 10 % gre temu, da imajo tekmovalci rešitev identično uradni
+		points = int(float64(input.Points) * parameters.WrongAnswerRatio * correctRatio(input))
+		result.Log += fmt.Sprintf("Wrong answer! Wrong test cases: %d, Correct test cases: %d. Applying partial points: %d!\n", input.WrongTestCases, input.CorrectTestCases, points)
+		result.Log += weightLog(input)
+	}
+
+	penalty := input.PreviousSubmissions * parameters.ResubmissionPenalty
+	newPoints := max(0, points-penalty)
+	result.Log += fmt.Sprintf("Applying penalty of %d points due to previous submissions! Points before: %d, Points after: %d.\n", penalty, points, newPoints)
+	points = newPoints
+
+	penalty = input.SubmittedAfter * parameters.TimePenalty
+	newPoints = max(0, points-penalty)
+	result.Log += fmt.Sprintf("Applying penalty of %d points due to time! Points before: %d, Points after: %d.\n", penalty, points, newPoints)
+
+	result.Score = newPoints
+	return result
+}
+
+// icpcScorer counts solved problems (AC or PART), with the penalty time being the submission time along with
+// AttemptPenaltyTime minutes for each previous attempt.
+type icpcScorer struct{}
+
+func (icpcScorer) Name() string {
+	return "icpc"
+}
+
+func (icpcScorer) Score(input Input, parameters Parameters) Result {
+	if input.Verdict != "AC" && input.Verdict != "PART" {
+		return Result{Log: "Problem was not solved! ICPC scoring gives no points.\n"}
+	}
+	penaltyTime := input.SubmittedAfter + input.PreviousSubmissions*parameters.AttemptPenaltyTime
+	return Result{
+		Score:       1,
+		PenaltyTime: penaltyTime,
+		Log:         fmt.Sprintf("Problem solved! Penalty time: %d minutes (submitted after %d minutes, %d previous attempts, %d minutes each).\n", penaltyTime, input.SubmittedAfter, input.PreviousSubmissions, parameters.AttemptPenaltyTime),
+	}
+}
+
+// Aggregate counts the first solving attempt, so that later attempts can't undo a solve. Its penalty time is
+// the submission time along with AttemptPenaltyTime minutes for each earlier rejected attempt. If the problem
+// wasn't solved, the latest attempt is counted without a penalty.
+func (icpcScorer) Aggregate(attempts []Attempt, parameters Parameters) (int, int) {
+	rejected := 0
+	for i, v := range attempts {
+		if v.Verdict == "AC" || v.Verdict == "PART" {
+			return i, v.SubmittedAfter + rejected*parameters.AttemptPenaltyTime
+		}
+		// neocenjene oddaje in napake ocenjevalnika se ne štejejo kot zavrnjeni poskusi
+		if v.Verdict != "PENDING" && v.Verdict != "JE" {
+			rejected++
+		}
+	}
+	return len(attempts) - 1, 0
+}
+
+// percentageScorer gives points in proportion to the correct test cases, without any penalties.
+type percentageScorer struct{}
+
+func (percentageScorer) Name() string {
+	return "percentage"
+}
+
+func (percentageScorer) Score(input Input, parameters Parameters) Result {
+	points := int(float64(input.Points) * correctRatio(input))
+	return Result{
+		Score: points,
+		Log:   fmt.Sprintf("Correct test cases: %d/%d. Awarding %d points.\n", input.CorrectTestCases, input.CorrectTestCases+input.WrongTestCases, points) + weightLog(input),
+	}
+}
+
+// allOrNothingScorer gives all points for an AC verdict and no points otherwise.
+type allOrNothingScorer struct{}
+
+func (allOrNothingScorer) Name() string {
+	return "all_or_nothing"
+}
+
+func (allOrNothingScorer) Score(input Input, parameters Parameters) Result {
+	if input.Verdict != "AC" {
+		return Result{Log: fmt.Sprintf("Verdict %s gives no points with all-or-nothing scoring.\n", input.Verdict)}
+	}
+	return Result{Score: input.Points, Log: fmt.Sprintf("Awarding all %d points.\n", input.Points)}
+}
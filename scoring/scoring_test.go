@@ -0,0 +1,105 @@
+package scoring
+
+import "testing"
+
+func TestDefaultScorer(t *testing.T) {
+	parameters := DefaultParameters()
+	parameters.TimePenalty = 2
+	parameters.TimePenaltyEach = 5
+
+	tests := []struct {
+		name  string
+		input Input
+		want  int
+	}{
+		{"AC", Input{Verdict: "AC", Points: 1000}, 1000},
+		{"PART", Input{Verdict: "PART", Points: 1000}, 900},
+		{"WA", Input{Verdict: "WA", Points: 1000, CorrectTestCases: 3, WrongTestCases: 1}, 472},
+		{"RTE", Input{Verdict: "RTE", Points: 1000}, 0},
+		{"resubmission penalty", Input{Verdict: "AC", Points: 1000, PreviousSubmissions: 2}, 940},
+		// časovna kazen je kot prej na minuto, penalty_each se ne upošteva
+		{"time penalty", Input{Verdict: "AC", Points: 1000, SubmittedAfter: 12}, 976},
+		{"never negative", Input{Verdict: "AC", Points: 100, PreviousSubmissions: 10}, 0},
+	}
+	for _, test := range tests {
+		if got := (defaultScorer{}).Score(test.input, parameters).Score; got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWeightedPartialScore(t *testing.T) {
+	parameters := DefaultParameters()
+	input := Input{Verdict: "WA", Points: 1000, CorrectTestCases: 1, WrongTestCases: 3, CorrectWeight: 9, TotalWeight: 10}
+	if got := (percentageScorer{}).Score(input, parameters).Score; got != 900 {
+		t.Errorf("weighted percentage: got %d, want 900", got)
+	}
+	input.MandatoryFailed = 1
+	if got := (percentageScorer{}).Score(input, parameters).Score; got != 0 {
+		t.Errorf("failed mandatory row: got %d, want 0", got)
+	}
+}
+
+func TestAllOrNothingScorer(t *testing.T) {
+	parameters := DefaultParameters()
+	if got := (allOrNothingScorer{}).Score(Input{Verdict: "AC", Points: 500}, parameters).Score; got != 500 {
+		t.Errorf("AC: got %d, want 500", got)
+	}
+	if got := (allOrNothingScorer{}).Score(Input{Verdict: "PART", Points: 500}, parameters).Score; got != 0 {
+		t.Errorf("PART: got %d, want 0", got)
+	}
+}
+
+func TestICPCAggregate(t *testing.T) {
+	parameters := DefaultParameters()
+	scorer, err := Get("icpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		attempts []Attempt
+		counted  int
+		penalty  int
+	}{
+		{"first try", []Attempt{{Verdict: "AC", Score: 1, SubmittedAfter: 10}}, 0, 10},
+		{"rejected tries", []Attempt{{Verdict: "WA", SubmittedAfter: 5}, {Verdict: "RTE", SubmittedAfter: 8}, {Verdict: "AC", Score: 1, SubmittedAfter: 30}}, 2, 70},
+		{"wrong answer after a solve", []Attempt{{Verdict: "AC", Score: 1, SubmittedAfter: 10}, {Verdict: "WA", SubmittedAfter: 20}}, 0, 10},
+		{"judge errors", []Attempt{{Verdict: "JE", SubmittedAfter: 5}, {Verdict: "PART", Score: 1, SubmittedAfter: 15}}, 1, 15},
+		{"unsolved", []Attempt{{Verdict: "WA", SubmittedAfter: 5}, {Verdict: "WA", SubmittedAfter: 9}}, 1, 0},
+	}
+	for _, test := range tests {
+		counted, penalty := Aggregate(scorer, test.attempts, parameters)
+		if counted != test.counted || penalty != test.penalty {
+			t.Errorf("%s: got attempt %d with penalty %d, want attempt %d with penalty %d", test.name, counted, penalty, test.counted, test.penalty)
+		}
+	}
+}
+
+func TestAggregateLatest(t *testing.T) {
+	scorer, err := Get("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attempts := []Attempt{{Verdict: "AC", Score: 100, PenaltyTime: 3}, {Verdict: "WA", Score: 40, PenaltyTime: 7}}
+	if counted, penalty := Aggregate(scorer, attempts, DefaultParameters()); counted != 1 || penalty != 7 {
+		t.Errorf("got attempt %d with penalty %d, want the latest one", counted, penalty)
+	}
+}
+
+func TestParseParameters(t *testing.T) {
+	parameters, err := ParseParameters(`{"part_ratio": 0.5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parameters.PartRatio != 0.5 || parameters.WrongAnswerRatio != DefaultParameters().WrongAnswerRatio {
+		t.Errorf("partial parameters weren't applied on top of the defaults: %+v", parameters)
+	}
+	if _, err := ParseParameters(`{"part_ratio": 2}`); err == nil {
+		t.Error("part_ratio out of range was accepted")
+	}
+	if _, err := Get("unknown"); err == nil {
+		t.Error("unknown policy was accepted")
+	}
+}
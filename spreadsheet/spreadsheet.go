@@ -0,0 +1,187 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxCellLength is the longest text, which spreadsheet programs accept in a single cell.
+const maxCellLength = 32767
+
+// Sheet is a table, whose first row is the header. Cells are strings or integers, anything else is written as text.
+type Sheet struct {
+	Name string
+	Rows [][]any
+}
+
+func cellText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// escapeFormula prefixes text, which a spreadsheet program would read as a formula from a CSV file, with an apostrophe.
+// Only strings are escaped, numbers are written as they are.
+func escapeFormula(v any, text string) string {
+	if _, ok := v.(string); ok && text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// WriteCSV writes the sheet as CSV. The UTF-8 byte order mark makes spreadsheet programs read č, š and ž correctly.
+func WriteCSV(w io.Writer, sheet Sheet) error {
+	_, err := io.WriteString(w, "\ufeff")
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	for _, row := range sheet.Rows {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = escapeFormula(v, cellText(v))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// columnName returns the column's letters, 0 is A, 26 is AA.
+func columnName(column int) string {
+	name := ""
+	for column >= 0 {
+		name = string(rune('A'+column%26)) + name
+		column = column/26 - 1
+	}
+	return name
+}
+
+func escape(s string) string {
+	var builder strings.Builder
+	xml.EscapeText(&builder, []byte(s))
+	return builder.String()
+}
+
+const contentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const rootRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRelationships = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+// styles has two cell formats, the default one and a bold one for the header.
+const styles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>
+</styleSheet>`
+
+func workbook(name string) string {
+	// imena listov so omejena na 31 znakov in ne smejo vsebovati nekaterih znakov
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="` + escape(name) + `" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+}
+
+func worksheet(sheet Sheet) string {
+	var builder strings.Builder
+	builder.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>
+<sheetData>`)
+	for i, row := range sheet.Rows {
+		builder.WriteString(fmt.Sprintf(`<row r="%d">`, i+1))
+		style := ""
+		if i == 0 {
+			style = ` s="1"`
+		}
+		for l, v := range row {
+			ref := columnName(l) + strconv.Itoa(i+1)
+			if number, ok := v.(int); ok {
+				builder.WriteString(fmt.Sprintf(`<c r="%s"%s><v>%d</v></c>`, ref, style, number))
+				continue
+			}
+			// besedilo v celici XLSX ni nikoli formula, zato ga ni treba ubežati
+			text := cellText(v)
+			if text == "" {
+				continue
+			}
+			if runes := []rune(text); len(runes) > maxCellLength {
+				text = string(runes[:maxCellLength])
+			}
+			builder.WriteString(fmt.Sprintf(`<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(text)))
+		}
+		builder.WriteString(`</row>`)
+	}
+	builder.WriteString(`</sheetData>
+</worksheet>`)
+	return builder.String()
+}
+
+// WriteXLSX writes the sheet as a single sheet Office Open XML workbook. The header row is bold and frozen.
+func WriteXLSX(w io.Writer, sheet Sheet) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes},
+		{"_rels/.rels", rootRelationships},
+		{"xl/workbook.xml", workbook(sheet.Name)},
+		{"xl/_rels/workbook.xml.rels", workbookRelationships},
+		{"xl/styles.xml", styles},
+		{"xl/worksheets/sheet1.xml", worksheet(sheet)},
+	}
+	for _, v := range files {
+		file, err := archive.Create(v.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(file, v.content)
+		if err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,66 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value any
+		want  string
+	}{
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@cmd", "'@cmd"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"Ekipa", "Ekipa"},
+		{"a=b", "a=b"},
+		{"", ""},
+		// števila niso ubežana
+		{-5, "-5"},
+	}
+	for _, v := range tests {
+		got := escapeFormula(v.value, cellText(v.value))
+		if got != v.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", v.value, got, v.want)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WriteCSV(&buffer, Sheet{Rows: [][]any{{"Ekipa", "Točke"}, {"=HYPERLINK(\"x\")", -3}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeffEkipa,Točke\n\"'=HYPERLINK(\"\"x\"\")\",-3\n"
+	if buffer.String() != want {
+		t.Errorf("got %q, want %q", buffer.String(), want)
+	}
+}
+
+func TestWorksheetKeepsText(t *testing.T) {
+	xml := worksheet(Sheet{Rows: [][]any{{"Ekipa"}, {"=1+1"}, {"-2"}, {7}}})
+	for _, want := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Ekipa</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">=1+1</t></is></c>`,
+		`<c r="A3" t="inlineStr"><is><t xml:space="preserve">-2</t></is></c>`,
+		`<c r="A4"><v>7</v></c>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("worksheet is missing %s", want)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for column, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(column); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", column, got, want)
+		}
+	}
+}